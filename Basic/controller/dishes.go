@@ -4,7 +4,6 @@ import (
 	"Food_recommendation/Basic/dao"
 	"Food_recommendation/Basic/model"
 	"Food_recommendation/utils"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
 	"net/http"
@@ -19,7 +18,7 @@ func GetADishes(c *gin.Context) {
 	// 调用DAO层获取包含标签的菜品数据
 	data, err := dao.GetADishes(c.Request.Context(), uint(SID), uint(DID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get dish", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 
@@ -64,14 +63,14 @@ func UpdateADishes(c *gin.Context) {
 	SID, _ := strconv.Atoi(c.Param("storeId"))
 	DID, _ := strconv.Atoi(c.Param("dishId"))
 	var d model.Dishes
-	if err := c.BindJSON(&d); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	if err := c.ShouldBindJSON(&d); err != nil {
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
 	d.ID = uint(DID)
 	d.StoreID = uint(SID)
 	if err := dao.UpdateDishes(c.Request.Context(), d); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update Dishes", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -81,13 +80,13 @@ func UpdateADishes(c *gin.Context) {
 func DeleteADishes(c *gin.Context) {
 	SID, _ := strconv.Atoi(c.Param("storeId"))
 	DID, _ := strconv.Atoi(c.Param("dishId"))
-	MID, _ := strconv.Atoi(utils.ParseSet(c))
-	if !dao.Check(c.Request.Context(), uint(SID), uint(MID)) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unauthorized"})
+	MID := utils.CurrentID(c)
+	if !dao.Check(c.Request.Context(), uint(SID), MID) {
+		utils.Fail(c, errStoreForbidden)
 		return
 	}
 	if err := dao.DeleteDishes(c.Request.Context(), uint(SID), uint(DID)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete Dishes", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -96,21 +95,19 @@ func DeleteADishes(c *gin.Context) {
 }
 func AddTags(c *gin.Context) {
 	SID, _ := strconv.Atoi(c.Param("storeId"))
-	DID, _ := strconv.Atoi(c.Param("dishId"))
-	if !dao.Check(c.Request.Context(), uint(SID), uint(DID)) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unauthorized"})
+	if !dao.Check(c.Request.Context(), uint(SID), utils.CurrentID(c)) {
+		utils.Fail(c, errStoreForbidden)
 		return
 	}
 	var req struct {
 		Tags []string `json:"tags" binding:"required,min=1"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json data"})
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
-	fmt.Println(req.Tags)
 	if err := dao.AddTags(c.Request.Context(), req.Tags); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "add tags failed"})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "tags added successfully"})
@@ -118,7 +115,7 @@ func AddTags(c *gin.Context) {
 func GetTags(c *gin.Context) {
 	data, err := dao.GetTags(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -130,9 +127,13 @@ func GetTags(c *gin.Context) {
 func ChooseTags(c *gin.Context) {
 	SID, _ := strconv.Atoi(c.Param("storeId"))
 	DID, _ := strconv.Atoi(c.Param("dishId"))
-	MID, _ := strconv.Atoi(utils.ParseSet(c))
-	if SID <= 0 || DID <= 0 || MID <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parameters"})
+	MID := utils.CurrentID(c)
+	if SID <= 0 {
+		utils.Fail(c, errInvalidStoreID)
+		return
+	}
+	if DID <= 0 {
+		utils.Fail(c, errInvalidDishID)
 		return
 	}
 	// 解析请求体
@@ -140,16 +141,16 @@ func ChooseTags(c *gin.Context) {
 		Tags []string `json:"tags" binding:"required,max=3"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
 	// 检查菜品是否存在且属于当前商户
-	if !dao.Check(c.Request.Context(), uint(SID), uint(MID)) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unauthorized"})
+	if !dao.Check(c.Request.Context(), uint(SID), MID) {
+		utils.Fail(c, errStoreForbidden)
 		return
 	}
 	if err := dao.ChooseTag(c.Request.Context(), uint(DID), req.Tags); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set tags", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
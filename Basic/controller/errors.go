@@ -0,0 +1,10 @@
+package controller
+
+import "Food_recommendation/Basic/model"
+
+// 控制器层的参数与权限错误
+var (
+	errStoreForbidden = model.Forbidden("store_forbidden", "store does not belong to current merchant")
+	errInvalidStoreID = model.InvalidField("storeId", "number", "")
+	errInvalidDishID  = model.InvalidField("dishId", "number", "")
+)
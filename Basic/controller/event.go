@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// PostEvents 批量接收推荐结果的曝光、点击、停留和不感兴趣事件，一次最多 100 条。
+// requestId 为返回推荐结果的 /api/user/recommend 响应中的 requestId
+func PostEvents(c *gin.Context) {
+	var req struct {
+		Events []struct {
+			Type      string `json:"type" binding:"required,oneof=impression click dwell dismiss"`
+			RequestID string `json:"requestId" binding:"max=64"`
+			DishID    uint   `json:"dishId" binding:"required"`
+			Position  int    `json:"position" binding:"min=0"`
+			DwellMs   int    `json:"dwellMs" binding:"min=0,required_if=Type dwell"`
+		} `json:"events" binding:"required,min=1,max=100,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, utils.BindError(err))
+		return
+	}
+
+	uid := utils.CurrentID(c)
+	events := make([]model.UserEvent, 0, len(req.Events))
+	for _, e := range req.Events {
+		events = append(events, model.UserEvent{
+			UserID:    uid,
+			RequestID: e.RequestID,
+			Type:      e.Type,
+			DishID:    e.DishID,
+			Position:  e.Position,
+			DwellMs:   e.DwellMs,
+		})
+	}
+	if err := dao.AddUserEvents(c.Request.Context(), events); err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Events recorded", "count": len(events)})
+}
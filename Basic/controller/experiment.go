@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+)
+
+var experiments []config.Experiment
+
+// InitExperiments 注入在线实验配置，须与推荐服务使用同一份，行为才会计入用户被分到的分组
+func InitExperiments(e []config.Experiment) {
+	experiments = e
+}
+
+// recordExposure 记录一次推荐曝光，只有推荐服务按实验分组返回了结果时才记录
+func recordExposure(c *gin.Context, uid uint, experiment, variant string, items int) {
+	if experiment == "" || items == 0 {
+		return
+	}
+	recordExperimentEvent(c, &model.ExperimentEvent{
+		Experiment: experiment,
+		Variant:    variant,
+		UserID:     uid,
+		Kind:       model.ExperimentExposure,
+		Items:      items,
+	})
+}
+
+// recordAction 记录实验用户的点赞、评分或店铺浏览，不在实验中的用户不记录
+func recordAction(c *gin.Context, uid uint, kind string, dishID, storeID uint) {
+	a, ok := utils.AssignExperiment(experiments, uid)
+	if !ok {
+		return
+	}
+	recordExperimentEvent(c, &model.ExperimentEvent{
+		Experiment: a.Experiment,
+		Variant:    a.Variant,
+		UserID:     uid,
+		Kind:       kind,
+		DishID:     dishID,
+		StoreID:    storeID,
+	})
+}
+
+// recordExperimentEvent 写入失败只记录日志，不影响接口本身
+func recordExperimentEvent(c *gin.Context, e *model.ExperimentEvent) {
+	ctx := c.Request.Context()
+	if err := dao.RecordExperimentEvent(ctx, e); err != nil {
+		slog.ErrorContext(ctx, "record experiment event failed", "experiment", e.Experiment, "kind", e.Kind, "err", err)
+	}
+}
+
+// ExperimentReport 按实验分组返回曝光、点击率和转化率，可用 experiment 参数只看一个实验
+func ExperimentReport(c *gin.Context) {
+	stats, err := dao.ExperimentReport(c.Request.Context(), c.Query("experiment"))
+	if err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	if stats == nil {
+		stats = []dao.ExperimentStats{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully get experiment report",
+		"data":    stats,
+	})
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	gen "Food_recommendation/Recom/proto/gen"
+	"Food_recommendation/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MarkNotInterested 标记不感兴趣的菜品、店铺或标签，推荐和搜索结果不再出现对应菜品。
+// 请求体可选，days 为有效天数，不传或为 0 时长期有效
+func MarkNotInterested(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Days int `json:"days" binding:"min=0,max=365"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			utils.Fail(c, utils.BindError(err))
+			return
+		}
+		targetID, err := notInterestedTarget(c, kind)
+		if err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		n := &model.NotInterested{UserID: utils.CurrentID(c), Kind: kind, TargetID: targetID}
+		if req.Days > 0 {
+			expires := time.Now().AddDate(0, 0, req.Days)
+			n.ExpiresAt = &expires
+		}
+		if err := dao.AddNotInterested(c.Request.Context(), n); err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Marked as not interested", "data": n})
+	}
+}
+
+// UnmarkNotInterested 撤销不感兴趣标记
+func UnmarkNotInterested(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetID, err := notInterestedTarget(c, kind)
+		if err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		if err := dao.RemoveNotInterested(c.Request.Context(), utils.CurrentID(c), kind, targetID); err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Not interested mark removed"})
+	}
+}
+
+// ListNotInterested 返回当前用户仍然有效的不感兴趣标记
+func ListNotInterested(c *gin.Context) {
+	items, err := dao.ListNotInterested(c.Request.Context(), utils.CurrentID(c))
+	if err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	if items == nil {
+		items = []dao.NotInterestedItem{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully get not interested list",
+		"data":    items,
+	})
+}
+
+// filterNotInterested 去掉用户标记为不感兴趣的菜品
+func filterNotInterested(c *gin.Context, uid uint, items []*gen.ShowMerchant) ([]*gen.ShowMerchant, error) {
+	ids := make([]uint, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, uint(it.DishesID))
+	}
+	visible, err := dao.VisibleDishIDs(c.Request.Context(), uid, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*gen.ShowMerchant, 0, len(items))
+	for _, it := range items {
+		if visible[uint(it.DishesID)] {
+			res = append(res, it)
+		}
+	}
+	return res, nil
+}
+
+// notInterestedTarget 从路径参数取得对象 ID，标签按名称查找
+func notInterestedTarget(c *gin.Context, kind string) (uint, error) {
+	switch kind {
+	case model.NotInterestedDish:
+		id, err := strconv.ParseUint(c.Param("dishId"), 10, 64)
+		if err != nil || id == 0 {
+			return 0, errInvalidDishID
+		}
+		return uint(id), nil
+	case model.NotInterestedStore:
+		id, err := strconv.ParseUint(c.Param("storeId"), 10, 64)
+		if err != nil || id == 0 {
+			return 0, errInvalidStoreID
+		}
+		return uint(id), nil
+	default:
+		return dao.TagID(c.Request.Context(), c.Param("tag"))
+	}
+}
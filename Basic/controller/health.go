@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"Food_recommendation/Basic/dao"
+	"context"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// readyTimeout 就绪检查中每个依赖的最长探测时间
+const readyTimeout = 2 * time.Second
+
+var draining atomic.Bool
+
+// SetDraining 标记服务正在退出，就绪探针随即返回 503，负载均衡不再转发新请求
+func SetDraining() {
+	draining.Store(true)
+}
+
+// Healthz 存活探针，进程能处理请求即返回 200
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz 就绪探针，数据库不可用时返回 503。推荐服务不可用时接口会返回热门菜品兜底，
+// 只把状态标记为 degraded，避免推荐服务故障导致所有 API 实例被摘除。
+// 探针是公开路由，错误详情（可能包含内部地址）只记录日志，响应中只返回 unavailable
+func Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	status := "ready"
+	checks := gin.H{"database": "ok", "recommend": "ok"}
+	if draining.Load() {
+		status = "unavailable"
+		checks["server"] = "shutting down"
+	}
+	if err := dao.Ping(ctx); err != nil {
+		status = "unavailable"
+		slog.WarnContext(ctx, "readiness check failed", "check", "database", "err", err)
+		checks["database"] = "unavailable"
+	}
+	if err := pingRecommend(ctx); err != nil {
+		if status == "ready" {
+			status = "degraded"
+		}
+		slog.WarnContext(ctx, "readiness check failed", "check", "recommend", "err", err)
+		checks["recommend"] = "unavailable"
+	}
+
+	if status == "unavailable" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": status, "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status, "checks": checks})
+}
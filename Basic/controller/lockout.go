@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"time"
+)
+
+var lockoutPolicy = config.Default().Lockout
+
+// InitLockout 注入登录失败锁定策略
+func InitLockout(c config.Lockout) {
+	lockoutPolicy = c
+}
+
+func errAccountLocked(remaining time.Duration) error {
+	return model.TooManyRequests("account_locked", "account temporarily locked due to too many failed logins", remaining)
+}
+
+// checkLoginLock 账号处于锁定期时返回 429，调用方应直接结束处理
+func checkLoginLock(c *gin.Context, role utils.Role, account string) bool {
+	remaining, err := dao.LoginLockRemaining(c.Request.Context(), role, account)
+	if err != nil {
+		utils.Fail(c, err)
+		return false
+	}
+	if remaining > 0 {
+		utils.Fail(c, errAccountLocked(remaining))
+		return false
+	}
+	return true
+}
+
+// finishLogin 根据登录结果更新失败计数，返回 false 时已写入响应
+func finishLogin(c *gin.Context, role utils.Role, account string, loginErr error) bool {
+	ctx := c.Request.Context()
+	if loginErr == nil {
+		if err := dao.ResetLoginFailures(ctx, role, account); err != nil {
+			slog.ErrorContext(ctx, "reset login failures failed", "role", role, "err", err)
+		}
+		return true
+	}
+	if !errors.Is(loginErr, dao.ErrBadCredentials) {
+		utils.Fail(c, loginErr)
+		return false
+	}
+	lock, err := dao.RecordLoginFailure(ctx, role, account, lockoutPolicy)
+	if err != nil {
+		slog.ErrorContext(ctx, "record login failure failed", "role", role, "err", err)
+	}
+	if lock > 0 {
+		utils.Fail(c, errAccountLocked(lock))
+		return false
+	}
+	utils.Fail(c, loginErr)
+	return false
+}
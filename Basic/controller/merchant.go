@@ -4,38 +4,39 @@ import (
 	"Food_recommendation/Basic/dao"
 	"Food_recommendation/Basic/model"
 	"Food_recommendation/utils"
+	"context"
 	"github.com/gin-gonic/gin"
-	"log"
+	"log/slog"
 	"net/http"
-	"strconv"
-	"strings"
 )
 
 func MerchantRegister(c *gin.Context) {
-	var m model.Merchant
-	if err := c.BindJSON(&m); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	var req struct {
+		model.Merchant
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
-	log.Printf("Parsed merchant: %+v", m)
+	m := req.Merchant
+	slog.DebugContext(c.Request.Context(), "merchant register", "merchant", m)
 
 	if m.MerchantName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Merchant name is required"})
+		utils.Fail(c, model.InvalidField("MerchantName", "required", ""))
 		return
 	}
 	if m.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		utils.Fail(c, model.InvalidField("Password", "required", ""))
 		return
 	}
 
-	// 调用创建商户函数
-	err := dao.MerchantCreate(c.Request.Context(), m)
+	// 校验验证码后在同一事务中作废验证码并创建商户，创建失败时验证码仍可使用
+	err := registerWithCode(c, utils.RoleMerchant, m.Phone, req.Code, func(ctx context.Context) error {
+		return dao.MerchantCreate(ctx, m)
+	})
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": "Merchant name already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create merchant", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 
@@ -49,41 +50,40 @@ func MerchantRegister(c *gin.Context) {
 }
 func MerchantLogin(c *gin.Context) {
 	var m model.Merchant
-	if err := c.BindJSON(&m); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	if err := c.ShouldBindJSON(&m); err != nil {
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
 	if m.MerchantName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Merchant name is required"})
+		utils.Fail(c, model.InvalidField("MerchantName", "required", ""))
 		return
 	}
 	if m.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		utils.Fail(c, model.InvalidField("Password", "required", ""))
 		return
 	}
-	m2, err := dao.CheckLogin(c, m)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Merchant login failed", "details": err.Error()})
+	if !checkLoginLock(c, utils.RoleMerchant, m.MerchantName) {
 		return
 	}
-	aToken, rToken, err := utils.GenToken(strconv.Itoa(int(m2.ID)))
+	m2, err := dao.CheckLogin(c.Request.Context(), m)
+	if !finishLogin(c, utils.RoleMerchant, m.MerchantName, err) {
+		return
+	}
+	pair, err := dao.IssueTokens(c.Request.Context(), utils.RoleMerchant, m2.ID, c.GetHeader(deviceHeader), c.Request.UserAgent())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to generate token", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Merchant login successfully",
-		"data": gin.H{
-			"aToken": aToken,
-			"rToken": rToken,
-		},
+		"data":    tokenData(pair),
 	})
 }
 
 func GetMerchant(c *gin.Context) {
-	m, err := dao.GetProfile(c.Request.Context(), utils.ParseSet(c))
+	m, err := dao.GetProfile(c.Request.Context(), utils.CurrentID(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Merchant not found", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -98,15 +98,15 @@ func GetMerchant(c *gin.Context) {
 
 func UpdateMerchant(c *gin.Context) {
 	var m model.Merchant
-	if err := c.BindJSON(&m); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	if err := c.ShouldBindJSON(&m); err != nil {
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
-	ID, _ := strconv.Atoi(utils.ParseSet(c))
-	m.ID = uint(ID)
+	ID := utils.CurrentID(c)
+	m.ID = ID
 	err := dao.UpdateProfile(c.Request.Context(), m)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update merchant", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
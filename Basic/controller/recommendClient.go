@@ -1,59 +1,62 @@
 package controller
 
 import (
-	gen "Food_recommendation/Recom/proto/gen"
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/Basic/recommender"
 	"Food_recommendation/utils"
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	"log"
 	"strconv"
-	"time"
 )
 
-func HandleItemCFRecommend(c *gin.Context) {
-	id, _ := strconv.Atoi(utils.ParseSet(c))
-	from, _ := strconv.Atoi(c.Query("from"))
-	to, _ := strconv.Atoi(c.Query("to"))
+// Recommender 推荐来源，recommender.Client 为 gRPC 实现，测试时可替换
+type Recommender interface {
+	Recommend(ctx context.Context, userID uint, from, to int) (recommender.Result, error)
+	Ping(ctx context.Context) error
+}
 
-	// 创建微服务客户端连接
-	conn, err := grpc.Dial(
-		"localhost:8088", // ItemCF服务地址和端口
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
-	if err != nil {
-		log.Printf("Failed to connect to ItemCF service: %v", err)
-		c.JSON(500, gin.H{"error": "Failed to connect to recommendation service"})
-		return
-	}
-	defer conn.Close()
+var recommendSvc Recommender
 
-	// 创建客户端
-	client := gen.NewRecommendServiceClient(conn)
+var errRecommendUnavailable = model.Unavailable("recommend_unavailable", "recommendation service is unavailable")
 
-	// 准备请求
-	itemCFReq := &gen.DishRecommendRequest{
-		UserID: uint32(id),
-		From:   uint32(from),
-		To:     uint32(to),
-	}
+// InitRecommend 注入推荐客户端
+func InitRecommend(r Recommender) {
+	recommendSvc = r
+}
 
-	// 设置超时
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func HandleItemCFRecommend(c *gin.Context) {
+	id := utils.CurrentID(c)
+	from, _ := strconv.Atoi(c.Query("from"))
+	to, _ := strconv.Atoi(c.Query("to"))
 
-	// 调用微服务
-	resp, err := client.DishRecommend(ctx, itemCFReq)
+	// 推荐服务不可用时客户端会返回热门菜品，source 标明结果来源
+	res, err := recommendSvc.Recommend(c.Request.Context(), id, from, to)
 	if err != nil {
-		log.Printf("ItemCF recommendation failed: %v", err)
-		c.JSON(500, gin.H{"error": "Recommendation service error"})
+		utils.Fail(c, errRecommendUnavailable.Wrap(err))
 		return
 	}
-	// 返回成功响应
+	// 推荐服务已过滤不感兴趣的菜品，热门兜底在这里过滤
+	if res.Fallback {
+		if res.Items, err = filterNotInterested(c, id, res.Items); err != nil {
+			utils.Fail(c, err)
+			return
+		}
+	}
+	recordExposure(c, id, res.Experiment, res.Variant, len(res.Items))
+	// requestId 供客户端上报曝光、点击等事件时关联本次推荐
 	c.JSON(200, gin.H{
-		"results": resp.Recommendations,
-		"count":   len(resp.Recommendations),
+		"results":   res.Items,
+		"count":     len(res.Items),
+		"source":    res.Source,
+		"requestId": utils.RequestIDFrom(c.Request.Context()),
 	})
 }
+
+// pingRecommend 检查推荐服务是否可用
+func pingRecommend(ctx context.Context) error {
+	if recommendSvc == nil {
+		return errors.New("recommend client is not initialized")
+	}
+	return recommendSvc.Ping(ctx)
+}
@@ -6,30 +6,30 @@ import (
 	"Food_recommendation/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
 func NewStore(c *gin.Context) {
 	var s model.Store
 	if err := c.ShouldBind(&s); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
-	if s.Name == "" || s.Description == "" || s.Address == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": "lack key information"})
+	var missing []model.FieldError
+	for _, f := range []struct{ name, value string }{{"name", s.Name}, {"description", s.Description}, {"address", s.Address}} {
+		if f.value == "" {
+			missing = append(missing, model.FieldError{Field: f.name, Rule: "required"})
+		}
+	}
+	if len(missing) > 0 {
+		utils.Fail(c, model.InvalidFields(missing...))
 		return
 	}
-	ID, _ := strconv.Atoi(utils.ParseSet(c))
-	s.MerchantID = uint(ID)
+	ID := utils.CurrentID(c)
+	s.MerchantID = ID
 	if err := dao.CreateStore(c.Request.Context(), s); err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": "Store name already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Store", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -37,10 +37,10 @@ func NewStore(c *gin.Context) {
 	})
 }
 func GetStores(c *gin.Context) {
-	ID, _ := strconv.Atoi(utils.ParseSet(c))
-	data, err := dao.MyStore(c.Request.Context(), uint(ID))
+	ID := utils.CurrentID(c)
+	data, err := dao.MyStore(c.Request.Context(), ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Store", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -49,20 +49,16 @@ func GetStores(c *gin.Context) {
 	})
 }
 func AStore(c *gin.Context) {
-	uid, _ := strconv.Atoi(utils.ParseSet(c))
+	role, uid := utils.CurrentPrincipal(c)
 	SID, err := strconv.Atoi(c.Param("storeId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID"})
+		utils.Fail(c, errInvalidStoreID)
 		return
 	}
 
 	store, err := dao.SearchStore(c.Request.Context(), uint(SID))
 	if err != nil {
-		log.Println(err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get store",
-			"details": err.Error(),
-		})
+		utils.Fail(c, err)
 		return
 	}
 
@@ -76,12 +72,13 @@ func AStore(c *gin.Context) {
 		"address":     store.Address,
 		"dishes":      formatDishes(store.Dishes),
 	}
-	if err = dao.AddHistory(c.Request.Context(), uint(uid), uint(SID)); err != nil {
-		log.Println(err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to add history",
-		})
-		return
+	// 商家查看店铺不记入浏览历史
+	if role == utils.RoleUser {
+		if err = dao.AddHistory(c.Request.Context(), uid, uint(SID)); err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		recordAction(c, uid, model.ExperimentVisit, 0, uint(SID))
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Successfully get store",
@@ -123,17 +120,17 @@ func formatDishes(dishes []model.Dishes) []gin.H {
 	return result
 }
 func UpdateStore(c *gin.Context) {
-	MID, _ := strconv.Atoi(utils.ParseSet(c))
+	MID := utils.CurrentID(c)
 	SID, _ := strconv.Atoi(c.Param("storeId"))
 	var s model.Store
 	if err := c.ShouldBind(&s); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
-	s.MerchantID = uint(MID)
+	s.MerchantID = MID
 	s.ID = uint(SID)
 	if err := dao.UpdateStore(c.Request.Context(), s); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update Store", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -141,10 +138,10 @@ func UpdateStore(c *gin.Context) {
 	})
 }
 func DeleteStore(c *gin.Context) {
-	MID, _ := strconv.Atoi(utils.ParseSet(c))
+	MID := utils.CurrentID(c)
 	SID, _ := strconv.Atoi(c.Param("storeId"))
-	if err := dao.DeleteStore(c.Request.Context(), uint(SID), (uint)(MID)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete Store", "details": err.Error()})
+	if err := dao.DeleteStore(c.Request.Context(), uint(SID), MID); err != nil {
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -155,7 +152,7 @@ func NewDishes(c *gin.Context) {
 	// 解析storeId参数
 	SID, err := strconv.Atoi(c.Param("storeId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid store ID", "details": err.Error()})
+		utils.Fail(c, errInvalidStoreID)
 		return
 	}
 
@@ -171,14 +168,14 @@ func NewDishes(c *gin.Context) {
 
 	var req RequestBody
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
 
 	// 转换价格为decimal.Decimal类型
 	price, err := decimal.NewFromString(req.Price)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price format", "details": err.Error()})
+		utils.Fail(c, model.InvalidField("price", "decimal", ""))
 		return
 	}
 
@@ -205,11 +202,7 @@ func NewDishes(c *gin.Context) {
 
 	// 调用DAO层函数创建菜品
 	if err := dao.CreateDishes(c.Request.Context(), dishes); err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": "Dishes name already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Dishes", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 
@@ -219,10 +212,10 @@ func NewDishes(c *gin.Context) {
 }
 func GetDishes(c *gin.Context) {
 	SID, _ := strconv.Atoi(c.Param("storeId"))
-	MID, _ := strconv.Atoi(utils.ParseSet(c))
-	data, err := dao.GetDishes(c.Request.Context(), uint(SID), uint(MID))
+	MID := utils.CurrentID(c)
+	data, err := dao.GetDishes(c.Request.Context(), uint(SID), MID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Dishes", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	var response []struct {
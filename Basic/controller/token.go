@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// deviceHeader 客户端设备标识，同一设备重新登录会替换旧会话
+const deviceHeader = "X-Device-ID"
+
+func tokenData(pair utils.TokenPair) gin.H {
+	return gin.H{
+		"aToken":          pair.AToken,
+		"rToken":          pair.RToken,
+		"aTokenExpiresAt": pair.AExpiresAt.Unix(),
+		"rTokenExpiresAt": pair.RExpiresAt.Unix(),
+	}
+}
+
+// RefreshToken 用 refresh_token 换取新的令牌对，旧的 refresh_token 随即失效
+func RefreshToken(role utils.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			RToken string `json:"rToken" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Fail(c, utils.BindError(err))
+			return
+		}
+		pair, err := dao.RotateRefreshToken(c.Request.Context(), role, req.RToken, c.Request.UserAgent())
+		if err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "token refreshed successfully",
+			"data":    tokenData(pair),
+		})
+	}
+}
+
+// Logout 注销当前会话，all=true 时注销该账号在所有设备上的会话
+func Logout(c *gin.Context) {
+	var err error
+	if c.Query("all") == "true" {
+		role, id := utils.CurrentPrincipal(c)
+		err = dao.RevokeAllSessions(c.Request.Context(), role, id)
+	} else {
+		err = dao.RevokeSession(c.Request.Context(), utils.CurrentSession(c))
+	}
+	if err != nil {
+		utils.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "logout successfully"})
+}
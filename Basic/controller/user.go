@@ -4,34 +4,36 @@ import (
 	"Food_recommendation/Basic/dao"
 	"Food_recommendation/Basic/model"
 	"Food_recommendation/utils"
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
 func UserRegister(c *gin.Context) {
-	var u model.User
-	if err := c.BindJSON(&u); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	var req struct {
+		model.User
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
+	u := req.User
 	if u.Username == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user name is required"})
+		utils.Fail(c, model.InvalidField("username", "required", ""))
 		return
 	}
 	if u.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+		utils.Fail(c, model.InvalidField("Password", "required", ""))
 		return
 	}
-	err := dao.CreateUser(c.Request.Context(), u)
+	err := registerWithCode(c, utils.RoleUser, u.Phone, req.Code, func(ctx context.Context) error {
+		return dao.CreateUser(ctx, u)
+	})
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": "user name already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{
@@ -40,51 +42,67 @@ func UserRegister(c *gin.Context) {
 }
 func UserLogin(c *gin.Context) {
 	var u model.User
-	if err := c.BindJSON(&u); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	if err := c.ShouldBindJSON(&u); err != nil {
+		utils.Fail(c, utils.BindError(err))
+		return
+	}
+	if u.Username == "" {
+		utils.Fail(c, model.InvalidField("username", "required", ""))
+		return
+	}
+	if u.Password == "" {
+		utils.Fail(c, model.InvalidField("Password", "required", ""))
 		return
 	}
-	if u.Username == "" || u.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "lack of data"})
+	if !checkLoginLock(c, utils.RoleUser, u.Username) {
 		return
 	}
 	user, err := dao.UserLogin(c.Request.Context(), u)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login", "details": err.Error()})
+	if !finishLogin(c, utils.RoleUser, u.Username, err) {
 		return
 	}
-	aToken, rToken, err := utils.GenToken(strconv.Itoa(int(user.ID)))
+	pair, err := dao.IssueTokens(c.Request.Context(), utils.RoleUser, user.ID, c.GetHeader(deviceHeader), c.Request.UserAgent())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	user.Password = ""
 	user.Phone = user.Phone[:3] + "****" + user.Phone[7:]
 	c.JSON(http.StatusOK, gin.H{
 		"message": "login successfully",
-		"data": gin.H{
-			"aToken": aToken,
-			"rToken": rToken,
-		},
-		"info": user,
+		"data":    tokenData(pair),
+		"info":    user,
 	})
 }
 
 func SearchHandler(c *gin.Context) {
 	keyword := c.Query("key")
 	token := c.GetHeader("Authorization")
-	uid := 0
+	var uid uint
 	if token != "" {
 		claim, err := utils.ParasToken(token)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login", "details": err.Error()})
+			utils.Fail(c, model.Unauthorized("token_invalid", "token is invalid or expired").Wrap(err))
+			return
+		}
+		// 与鉴权中间件一致，已登出的令牌不能再以该用户身份搜索
+		revoked, err := utils.DenyList.IsRevoked(c.Request.Context(), claim.Id)
+		if err != nil {
+			utils.Fail(c, fmt.Errorf("check token deny list: %w", err))
+			return
+		}
+		if revoked {
+			utils.Fail(c, model.Unauthorized("token_revoked", "token has been revoked"))
 			return
 		}
-		uid, _ = strconv.Atoi(claim.ID)
+		// 只有普通用户令牌会记录搜索历史，其他主体按匿名搜索处理
+		if role, id, err := claim.Principal(); err == nil && role == utils.RoleUser {
+			uid = id
+		}
 	}
-	results, err := dao.UserSearch(c.Request.Context(), keyword, uint(uid))
+	results, err := dao.UserSearch(c.Request.Context(), keyword, uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "搜索失败"})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -97,7 +115,7 @@ func DishHandler(c *gin.Context) {
 	SID, _ := strconv.Atoi(c.Param("storeId"))
 	data, err := dao.GetADishes(c.Request.Context(), uint(SID), uint(DID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -115,7 +133,7 @@ func DishHandler(c *gin.Context) {
 }
 
 //	func Rating(c *gin.Context) {
-//		uid, _ := strconv.Atoi(utils.ParseSet(c))
+//		uid := utils.CurrentID(c)
 //		DID, _ := strconv.Atoi(c.Param("dishId"))
 //		SID, _ := strconv.Atoi(c.Param("storeId"))
 //	}
@@ -124,15 +142,18 @@ func LikeDishHandler(c *gin.Context) {
 		DishID uint `json:"dishId"`
 		IsLike bool `json:"isLike"` // true=点赞，false=取消点赞
 	}
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
-	userID, _ := strconv.Atoi(utils.ParseSet(c))
-	if err := dao.LikeDish(c.Request.Context(), uint(userID), req.DishID, req.IsLike); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Like failed", "details": err.Error()})
+	userID := utils.CurrentID(c)
+	if err := dao.LikeDish(c.Request.Context(), userID, req.DishID, req.IsLike); err != nil {
+		utils.Fail(c, err)
 		return
 	}
+	if req.IsLike {
+		recordAction(c, userID, model.ExperimentLike, req.DishID, 0)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Like operation succeeded"})
 }
@@ -143,25 +164,25 @@ func RateDishHandler(c *gin.Context) {
 		Commit string `json:"commit"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		utils.Fail(c, utils.BindError(err))
 		return
 	}
 
-	fmt.Println(req)
-	userID, _ := strconv.Atoi(utils.ParseSet(c))
+	userID := utils.CurrentID(c)
 
-	if err := dao.RateDish(c.Request.Context(), uint(userID), req.DishID, req.Score, req.Commit); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rate failed", "details": err.Error()})
+	if err := dao.RateDish(c.Request.Context(), userID, req.DishID, req.Score, req.Commit); err != nil {
+		utils.Fail(c, err)
 		return
 	}
+	recordAction(c, userID, model.ExperimentRating, req.DishID, 0)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Rate submitted successfully", "avgRating": req.Score})
 }
 func GetHistory(c *gin.Context) {
-	userID, _ := strconv.Atoi(utils.ParseSet(c))
-	res, err := dao.GetUserHistory(c.Request.Context(), uint(userID))
+	userID := utils.CurrentID(c)
+	res, err := dao.GetUserHistory(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user history", "details": err.Error()})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -170,10 +191,10 @@ func GetHistory(c *gin.Context) {
 	})
 }
 func GetSearchKey(c *gin.Context) {
-	uid, _ := strconv.Atoi(utils.ParseSet(c))
-	res, err := dao.AllSearch(c.Request.Context(), uint(uid))
+	uid := utils.CurrentID(c)
+	res, err := dao.AllSearch(c.Request.Context(), uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed"})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -182,10 +203,10 @@ func GetSearchKey(c *gin.Context) {
 	})
 }
 func UserLike(c *gin.Context) {
-	uid, _ := strconv.Atoi(utils.ParseSet(c))
-	res, err := dao.AllLike(c.Request.Context(), uint(uid))
+	uid := utils.CurrentID(c)
+	res, err := dao.AllLike(c.Request.Context(), uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed"})
+		utils.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
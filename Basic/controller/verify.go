@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/Basic/verify"
+	"Food_recommendation/utils"
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+var verifier *verify.Service
+
+// InitVerify 注入验证码服务
+func InitVerify(s *verify.Service) {
+	verifier = s
+}
+
+// codePurpose 按主体类型区分验证码用途，用户和商家的验证码互不通用
+func codePurpose(role utils.Role, purpose string) string {
+	return string(role) + ":" + purpose
+}
+
+// registerWithCode 注册时校验验证码，作废验证码和 create 在同一事务中执行；配置关闭时直接执行 create
+func registerWithCode(c *gin.Context, role utils.Role, phone, code string, create func(ctx context.Context) error) error {
+	if !verifier.RequireOnRegister() {
+		return create(c.Request.Context())
+	}
+	if code == "" {
+		return model.InvalidField("code", "required", "")
+	}
+	return verifier.Check(c.Request.Context(), phone, codePurpose(role, verify.PurposeRegister), code, create)
+}
+
+// SendCode 发送注册或重置密码验证码
+func SendCode(role utils.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Phone   string `json:"phone" binding:"required"`
+			Purpose string `json:"purpose" binding:"required,oneof=register reset_password"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Fail(c, utils.BindError(err))
+			return
+		}
+		if !model.ValidPhone(req.Phone) {
+			utils.Fail(c, model.InvalidField("phone", "phone", ""))
+			return
+		}
+		if req.Purpose == verify.PurposeResetPassword {
+			// 未注册的手机号也返回成功，避免被用来探测账号是否存在
+			registered, err := dao.PhoneRegistered(c.Request.Context(), role, req.Phone)
+			if err != nil {
+				utils.Fail(c, err)
+				return
+			}
+			if !registered {
+				c.JSON(http.StatusOK, gin.H{"message": "verification code sent"})
+				return
+			}
+		}
+		if err := verifier.Send(c.Request.Context(), req.Phone, codePurpose(role, req.Purpose)); err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "verification code sent"})
+	}
+}
+
+// ResetPassword 使用验证码重置密码，成功后该账号所有设备需要重新登录
+func ResetPassword(role utils.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Phone    string `json:"phone" binding:"required"`
+			Code     string `json:"code" binding:"required"`
+			Password string `json:"password" binding:"required,min=8"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Fail(c, utils.BindError(err))
+			return
+		}
+		err := verifier.Check(c.Request.Context(), req.Phone, codePurpose(role, verify.PurposeResetPassword), req.Code, func(ctx context.Context) error {
+			return dao.ResetPassword(ctx, role, req.Phone, req.Password)
+		})
+		if err != nil {
+			utils.Fail(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
+	}
+}
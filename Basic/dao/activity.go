@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Activity 带时间的用户行为记录，用于推荐模型的离线评估。点赞和评分带菜品所在店铺，
+// 浏览记录只有店铺，搜索记录只有关键词；推荐反馈事件带 Type、RequestID、Position 和 DwellMs
+type Activity struct {
+	Type      string
+	UserID    uint
+	DishID    uint
+	StoreID   uint
+	Rating    uint
+	Keyword   string
+	RequestID string
+	Position  int
+	DwellMs   int
+	CreatedAt time.Time
+}
+
+// LikeActivities 返回所有点赞记录，按时间排序
+func LikeActivities(ctx context.Context) ([]Activity, error) {
+	var res []Activity
+	err := DB.WithContext(ctx).Model(&model.Like{}).
+		Select("likes.user_id, likes.dish_id, dishes.store_id, likes.created_at").
+		Joins("JOIN dishes ON dishes.id = likes.dish_id").
+		Order("likes.created_at, likes.id").
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("query like activities failed: %w", err)
+	}
+	return res, nil
+}
+
+// RatingActivities 返回所有评分记录，按时间排序
+func RatingActivities(ctx context.Context) ([]Activity, error) {
+	var res []Activity
+	err := DB.WithContext(ctx).Model(&model.Rating{}).
+		Select("ratings.user_id, ratings.dish_id, dishes.store_id, ratings.num AS rating, ratings.created_at").
+		Joins("JOIN dishes ON dishes.id = ratings.dish_id").
+		Order("ratings.created_at, ratings.id").
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("query rating activities failed: %w", err)
+	}
+	return res, nil
+}
+
+// HistoryActivities 返回所有店铺浏览记录，按时间排序
+func HistoryActivities(ctx context.Context) ([]Activity, error) {
+	var res []Activity
+	err := DB.WithContext(ctx).Model(&model.History{}).
+		Select("user_id, store_id, created_at").
+		Order("created_at, id").
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("query history activities failed: %w", err)
+	}
+	return res, nil
+}
+
+// SearchActivities 返回所有搜索记录，按时间排序
+func SearchActivities(ctx context.Context) ([]Activity, error) {
+	var res []Activity
+	err := DB.WithContext(ctx).Model(&model.Search{}).
+		Select("user_id, `key` AS keyword, created_at").
+		Order("created_at, id").
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("query search activities failed: %w", err)
+	}
+	return res, nil
+}
+
+// EventActivities 返回所有推荐反馈事件，按时间排序
+func EventActivities(ctx context.Context) ([]Activity, error) {
+	var res []Activity
+	err := DB.WithContext(ctx).Model(&model.UserEvent{}).
+		Select("user_events.type, user_events.user_id, user_events.dish_id, dishes.store_id, " +
+			"user_events.request_id, user_events.position, user_events.dwell_ms, user_events.created_at").
+		Joins("LEFT JOIN dishes ON dishes.id = user_events.dish_id").
+		Order("user_events.created_at, user_events.id").
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("query event activities failed: %w", err)
+	}
+	return res, nil
+}
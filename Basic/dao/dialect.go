@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// Dialect 隔离不同数据库的 SQL 方言差异，DAO 中所有非标准 SQL 片段都从这里获取
+type Dialect interface {
+	Name() string
+	// Open 根据 DSN 创建 GORM 驱动
+	Open(dsn string) gorm.Dialector
+	// MaxOpenConns 驱动对连接数的上限，0 表示不限制
+	MaxOpenConns() int
+	// Random 随机排序表达式
+	Random() string
+	// FormatDecimal 将数值表达式格式化为保留 digits 位小数的字符串
+	FormatDecimal(expr string, digits int) string
+	// Concat 拼接字符串表达式
+	Concat(parts ...string) string
+	// InsertIgnore 忽略唯一键冲突的 INSERT 前缀
+	InsertIgnore() string
+}
+
+var dialect Dialect = mysqlDialect{}
+
+// NewDialect 根据驱动名返回方言实现
+func NewDialect(driver string) (Dialect, error) {
+	switch driver {
+	case "", "mysql":
+		return mysqlDialect{}, nil
+	case "sqlite":
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Open(dsn string) gorm.Dialector {
+	return mysql.New(mysql.Config{
+		DSN: dsn,
+		// 高并发场景关键配置
+		DefaultStringSize:         256,  // 字符串字段默认长度
+		DisableDatetimePrecision:  true, // 禁用 datetime 精度（提升性能）
+		DontSupportRenameColumn:   true, // 禁止重命名列（避免潜在锁问题）
+		DontSupportRenameIndex:    true, // 禁止重命名索引
+		SkipInitializeWithVersion: false,
+	})
+}
+
+func (mysqlDialect) MaxOpenConns() int { return 0 }
+
+func (mysqlDialect) Random() string { return "RAND()" }
+
+func (mysqlDialect) FormatDecimal(expr string, digits int) string {
+	return fmt.Sprintf("FORMAT(%s, %d)", expr, digits)
+}
+
+func (mysqlDialect) Concat(parts ...string) string {
+	return "CONCAT(" + strings.Join(parts, ", ") + ")"
+}
+
+func (mysqlDialect) InsertIgnore() string { return "INSERT IGNORE" }
+
+// sqliteDialect 纯 Go 的 SQLite 驱动，用于本地开发和 CI，DSN 可以是文件路径或 ":memory:"
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Open(dsn string) gorm.Dialector {
+	return sqlite.Open(dsn)
+}
+
+// MaxOpenConns SQLite 只允许单写者，内存库的每个连接还是独立的数据库，因此只保留一个连接
+func (sqliteDialect) MaxOpenConns() int { return 1 }
+
+func (sqliteDialect) Random() string { return "RANDOM()" }
+
+func (sqliteDialect) FormatDecimal(expr string, digits int) string {
+	return fmt.Sprintf("printf('%%.%df', %s)", digits, expr)
+}
+
+func (sqliteDialect) Concat(parts ...string) string {
+	return "(" + strings.Join(parts, " || ") + ")"
+}
+
+func (sqliteDialect) InsertIgnore() string { return "INSERT OR IGNORE" }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"log/slog"
 	"strings"
 	"time"
 )
@@ -55,7 +56,7 @@ func CreateDishes(ctx context.Context, dish model.Dishes) error {
 			}
 		}
 
-		if err := tx.Exec("INSERT INTO dishes_tags (dishes_id, tag_id) VALUES (?, ?) ON DUPLICATE KEY UPDATE dishes_id=dishes_id",
+		if err := tx.Exec(dialect.InsertIgnore()+" INTO dishes_tags (dishes_id, tag_id) VALUES (?, ?)",
 			dish.ID, t.ID).Error; err != nil {
 			return fmt.Errorf("关联标签失败: %w", err)
 		}
@@ -67,7 +68,7 @@ func CreateDishes(ctx context.Context, dish model.Dishes) error {
 func GetDishes(ctx context.Context, SID uint, MID uint) ([]model.Dishes, error) {
 	var dishes []model.Dishes
 	if SID == 0 {
-		return dishes, errors.New("store ID is required")
+		return dishes, model.InvalidField("storeId", "required", "")
 	}
 	query := DB.WithContext(ctx).
 		Select("id", "store_id", "name", "price", "desc", "image_url", "available"). // 指定查询字段
@@ -86,10 +87,10 @@ func GetADishes(ctx context.Context, SID uint, DID uint) (model.Dishes, error) {
 
 	// 参数校验
 	if SID == 0 {
-		return dish, errors.New("store ID is required")
+		return dish, model.InvalidField("storeId", "required", "")
 	}
 	if DID == 0 {
-		return dish, errors.New("dish ID is required")
+		return dish, model.InvalidField("dishId", "required", "")
 	}
 
 	// 预加载标签关联数据
@@ -102,7 +103,7 @@ func GetADishes(ctx context.Context, SID uint, DID uint) (model.Dishes, error) {
 	// 错误处理
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return dish, errors.New("dish not found")
+			return dish, model.ErrDishNotFound
 		}
 		return dish, fmt.Errorf("database query failed: %w", result.Error)
 	}
@@ -111,12 +112,12 @@ func GetADishes(ctx context.Context, SID uint, DID uint) (model.Dishes, error) {
 }
 func UpdateDishes(ctx context.Context, d model.Dishes) error {
 	if d.ID == 0 {
-		return errors.New("dish ID is required")
+		return model.InvalidField("dishId", "required", "")
 	}
 	var originalDish model.Dishes
 	if err := DB.WithContext(ctx).Where("id = ?", d.ID).First(&originalDish).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("dish not found")
+			return model.ErrDishNotFound
 		}
 		return fmt.Errorf("query original dish failed: %w", err)
 	}
@@ -155,16 +156,16 @@ func UpdateDishes(ctx context.Context, d model.Dishes) error {
 		return fmt.Errorf("update failed: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("concurrent update conflict")
+		return model.VersionConflict("dish has been modified, please refresh and try again")
 	}
 	return nil
 }
 func DeleteDishes(ctx context.Context, SID uint, DID uint) error {
 	if SID == 0 {
-		return errors.New("store ID is required")
+		return model.InvalidField("storeId", "required", "")
 	}
 	if DID == 0 {
-		return errors.New("dish ID is required")
+		return model.InvalidField("dishId", "required", "")
 	}
 	tx := DB.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -180,7 +181,7 @@ func DeleteDishes(ctx context.Context, SID uint, DID uint) error {
 	if err := tx.Where("id = ? AND store_id = ?", DID, SID).First(&dish).Error; err != nil {
 		tx.Rollback()
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("dish not found or unauthorized")
+			return model.ErrDishNotFound
 		}
 		return fmt.Errorf("query dish failed: %w", err)
 	}
@@ -255,7 +256,10 @@ func LikeDish(ctx context.Context, userID, dishID uint, isLike bool) error {
 	// 检查菜品是否存在
 	var dish model.Dishes
 	if err := tx.First(&dish, dishID).Error; err != nil {
-		return fmt.Errorf("dish not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrDishNotFound
+		}
+		return fmt.Errorf("query dish failed: %w", err)
 	}
 
 	// 更新点赞数，确保不会小于0
@@ -313,7 +317,7 @@ func RateDish(ctx context.Context, userID, dishID uint, score uint, commit strin
 		Scan(&result).Error; err != nil {
 		return fmt.Errorf("calculate rating failed: %w", err)
 	}
-	fmt.Printf("Calculated rating sum: %d, count: %d\n", result.Sum, result.Num)
+	slog.DebugContext(ctx, "dish rating recalculated", "dish_id", dishID, "rating_sum", result.Sum, "rating_num", result.Num)
 
 	// 3. 更新菜品评分字段
 	var avgRating float64
@@ -336,7 +340,10 @@ func RateDish(ctx context.Context, userID, dishID uint, score uint, commit strin
 	// 获取菜品所属店铺ID
 	var dish model.Dishes
 	if err := tx.First(&dish, dishID).Error; err != nil {
-		return fmt.Errorf("dish not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrDishNotFound
+		}
+		return fmt.Errorf("query dish failed: %w", err)
 	}
 
 	// 计算店铺的总评分和评论数
@@ -387,3 +394,139 @@ func GetAllDishes(ctx context.Context) ([]model.Dishes, error) {
 	}
 	return dishes, nil
 }
+
+// PopularDishes 按点赞数和评分返回营业店铺中在售的热门菜品，预加载 Store，用于推荐服务不可用时兜底
+func PopularDishes(ctx context.Context, limit int) ([]model.Dishes, error) {
+	return popularDishes(availableDishes(ctx), limit)
+}
+
+// PopularDishesWithTags 同 PopularDishes，另预加载 Tags，供推荐服务按标签做多样性重排
+func PopularDishesWithTags(ctx context.Context, limit int) ([]model.Dishes, error) {
+	return popularDishes(availableDishes(ctx).Preload("Tags"), limit)
+}
+
+func popularDishes(tx *gorm.DB, limit int) ([]model.Dishes, error) {
+	var dishes []model.Dishes
+	err := tx.Order("dishes.like_num DESC, dishes.avg_rating DESC, dishes.id").
+		Limit(limit).
+		Find(&dishes).Error
+	if err != nil {
+		return nil, fmt.Errorf("query popular dishes failed: %w", err)
+	}
+	return dishes, nil
+}
+
+// AvailableDishesByID 返回指定 ID 中营业店铺在售的菜品，预加载 Store 和 Tags（推荐结果的多样性重排按标签计算相似度）
+func AvailableDishesByID(ctx context.Context, ids []uint) ([]model.Dishes, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var dishes []model.Dishes
+	if err := availableDishes(ctx).Preload("Tags").Where("dishes.id IN ?", ids).Find(&dishes).Error; err != nil {
+		return nil, fmt.Errorf("query dishes by id failed: %w", err)
+	}
+	return dishes, nil
+}
+
+// CatalogDishes 返回营业店铺中所有在售的菜品，预加载 Store 和 Tags，用于构建内容特征
+func CatalogDishes(ctx context.Context) ([]model.Dishes, error) {
+	var dishes []model.Dishes
+	if err := availableDishes(ctx).Preload("Tags").Order("dishes.id").Find(&dishes).Error; err != nil {
+		return nil, fmt.Errorf("query dish catalog failed: %w", err)
+	}
+	return dishes, nil
+}
+
+// availableDishes 营业店铺中在售菜品的查询，预加载 Store；需要标签的调用方自行 Preload("Tags")
+func availableDishes(ctx context.Context) *gorm.DB {
+	return DB.WithContext(ctx).
+		Preload("Store").
+		Joins("JOIN stores ON stores.id = dishes.store_id AND stores.active = ?", true).
+		Where("dishes.available = ?", true)
+}
+
+// AllLikes 返回所有用户的点赞记录（只含用户和菜品），用于构建用户×菜品矩阵
+func AllLikes(ctx context.Context) ([]model.Like, error) {
+	return likes(DB.WithContext(ctx))
+}
+
+// UserLikes 返回用户的点赞记录（只含用户和菜品）
+func UserLikes(ctx context.Context, uid uint) ([]model.Like, error) {
+	return likes(DB.WithContext(ctx).Where("user_id = ?", uid))
+}
+
+// AllRatings 返回所有用户的评分记录（只含用户、菜品和分数），用于构建用户×菜品矩阵
+func AllRatings(ctx context.Context) ([]model.Rating, error) {
+	return ratings(DB.WithContext(ctx))
+}
+
+// UserRatings 返回用户的评分记录（只含用户、菜品和分数）
+func UserRatings(ctx context.Context, uid uint) ([]model.Rating, error) {
+	return ratings(DB.WithContext(ctx).Where("user_id = ?", uid))
+}
+
+func likes(tx *gorm.DB) ([]model.Like, error) {
+	var likes []model.Like
+	if err := tx.Select("user_id", "dish_id").Find(&likes).Error; err != nil {
+		return nil, fmt.Errorf("query likes failed: %w", err)
+	}
+	return likes, nil
+}
+
+func ratings(tx *gorm.DB) ([]model.Rating, error) {
+	var ratings []model.Rating
+	if err := tx.Select("user_id", "dish_id", "num").Find(&ratings).Error; err != nil {
+		return nil, fmt.Errorf("query ratings failed: %w", err)
+	}
+	return ratings, nil
+}
+
+// StoreVisit 用户浏览某个店铺的次数
+type StoreVisit struct {
+	UserID  uint
+	StoreID uint
+	Visits  uint
+}
+
+// AllStoreVisits 按用户和店铺汇总所有浏览记录
+func AllStoreVisits(ctx context.Context) ([]StoreVisit, error) {
+	return storeVisits(DB.WithContext(ctx))
+}
+
+// UserStoreVisits 按店铺汇总用户的浏览记录
+func UserStoreVisits(ctx context.Context, uid uint) ([]StoreVisit, error) {
+	return storeVisits(DB.WithContext(ctx).Where("user_id = ?", uid))
+}
+
+func storeVisits(tx *gorm.DB) ([]StoreVisit, error) {
+	var visits []StoreVisit
+	err := tx.Model(&model.History{}).
+		Select("user_id, store_id, COUNT(*) AS visits").
+		Group("user_id, store_id").
+		Scan(&visits).Error
+	if err != nil {
+		return nil, fmt.Errorf("query store visits failed: %w", err)
+	}
+	return visits, nil
+}
+
+// AvailableDishIDsByStore 返回指定店铺在售菜品的 ID，按店铺分组
+func AvailableDishIDsByStore(ctx context.Context, storeIDs []uint) (map[uint][]uint, error) {
+	res := make(map[uint][]uint)
+	if len(storeIDs) == 0 {
+		return res, nil
+	}
+	var dishes []model.Dishes
+	err := DB.WithContext(ctx).
+		Select("id", "store_id").
+		Where("store_id IN ? AND available = ?", storeIDs, true).
+		Order("id").
+		Find(&dishes).Error
+	if err != nil {
+		return nil, fmt.Errorf("query store dishes failed: %w", err)
+	}
+	for _, d := range dishes {
+		res[d.StoreID] = append(res[d.StoreID], d.ID)
+	}
+	return res, nil
+}
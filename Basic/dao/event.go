@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// eventBatchSize 事件每批插入的行数
+const eventBatchSize = 500
+
+// EventSignal 用户对某个菜品的点击次数和不感兴趣次数
+type EventSignal struct {
+	UserID    uint
+	DishID    uint
+	Clicks    uint
+	Dismisses uint
+}
+
+// AddUserEvents 追加一批推荐反馈事件，有菜品不存在时整批拒绝并返回 ErrDishNotFound
+func AddUserEvents(ctx context.Context, events []model.UserEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make(map[uint]bool, len(events))
+	for _, e := range events {
+		ids[e.DishID] = true
+	}
+	dishIDs := make([]uint, 0, len(ids))
+	for id := range ids {
+		dishIDs = append(dishIDs, id)
+	}
+	var count int64
+	if err := DB.WithContext(ctx).Model(&model.Dishes{}).Where("id IN ?", dishIDs).Count(&count).Error; err != nil {
+		return fmt.Errorf("query dishes failed: %w", err)
+	}
+	if int(count) != len(dishIDs) {
+		return model.ErrDishNotFound
+	}
+	if err := DB.WithContext(ctx).CreateInBatches(events, eventBatchSize).Error; err != nil {
+		return fmt.Errorf("insert user events failed: %w", err)
+	}
+	return nil
+}
+
+// AllEventSignals 按用户和菜品汇总所有点击和不感兴趣事件
+func AllEventSignals(ctx context.Context) ([]EventSignal, error) {
+	return eventSignals(DB.WithContext(ctx))
+}
+
+// UserEventSignals 按菜品汇总用户的点击和不感兴趣事件
+func UserEventSignals(ctx context.Context, uid uint) ([]EventSignal, error) {
+	return eventSignals(DB.WithContext(ctx).Where("user_id = ?", uid))
+}
+
+func eventSignals(tx *gorm.DB) ([]EventSignal, error) {
+	var signals []EventSignal
+	err := tx.Model(&model.UserEvent{}).
+		Select("user_id, dish_id, "+
+			"SUM(CASE WHEN type = ? THEN 1 ELSE 0 END) AS clicks, "+
+			"SUM(CASE WHEN type = ? THEN 1 ELSE 0 END) AS dismisses", model.EventClick, model.EventDismiss).
+		Where("type IN ?", []string{model.EventClick, model.EventDismiss}).
+		Group("user_id, dish_id").
+		Scan(&signals).Error
+	if err != nil {
+		return nil, fmt.Errorf("query event signals failed: %w", err)
+	}
+	return signals, nil
+}
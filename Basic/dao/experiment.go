@@ -0,0 +1,132 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+)
+
+// ExperimentStats 一个实验分组的曝光和转化统计。行为只统计在同一分组中有过曝光、且发生在首次曝光之后的部分
+type ExperimentStats struct {
+	Experiment string  `json:"experiment"`
+	Variant    string  `json:"variant"`
+	Exposures  int64   `json:"exposures"` // 推荐接口返回结果的次数
+	Users      int64   `json:"users"`     // 有过曝光的用户数
+	Items      int64   `json:"items"`     // 曝光的菜品总数
+	Visits     int64   `json:"visits"`
+	Likes      int64   `json:"likes"`
+	Ratings    int64   `json:"ratings"`
+	Converted  int64   `json:"converted"`  // 点赞或评分过的用户数
+	CTR        float64 `json:"ctr"`        // 店铺浏览次数 / 曝光次数
+	Conversion float64 `json:"conversion"` // 转化用户数 / 曝光用户数
+}
+
+// RecordExperimentEvent 追加一条实验曝光或行为记录
+func RecordExperimentEvent(ctx context.Context, e *model.ExperimentEvent) error {
+	if err := DB.WithContext(ctx).Create(e).Error; err != nil {
+		return fmt.Errorf("record experiment event failed: %w", err)
+	}
+	return nil
+}
+
+// ExperimentReport 按实验和分组汇总曝光和行为，experiment 为空时返回全部实验
+func ExperimentReport(ctx context.Context, experiment string) ([]ExperimentStats, error) {
+	db := DB.WithContext(ctx)
+	exposures := db.Model(&model.ExperimentEvent{}).Where("kind = ?", model.ExperimentExposure)
+	if experiment != "" {
+		exposures = exposures.Where("experiment = ?", experiment)
+	}
+	var stats []ExperimentStats
+	err := exposures.
+		Select("experiment, variant, COUNT(*) AS exposures, COUNT(DISTINCT user_id) AS users, COALESCE(SUM(items), 0) AS items").
+		Group("experiment, variant").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("query experiment exposures failed: %w", err)
+	}
+
+	// 每个用户在分组中的首次曝光时间
+	first := db.Model(&model.ExperimentEvent{}).
+		Select("experiment, variant, user_id, MIN(created_at) AS first_at").
+		Where("kind = ?", model.ExperimentExposure).
+		Group("experiment, variant, user_id")
+	// actions 首次曝光之后的行为，每次查询新建语句，避免条件互相累加
+	actions := func() *gorm.DB {
+		q := db.Table("experiment_events AS a").
+			Joins("JOIN (?) AS e ON e.experiment = a.experiment AND e.variant = a.variant AND e.user_id = a.user_id", first).
+			Where("a.kind <> ? AND a.created_at >= e.first_at", model.ExperimentExposure)
+		if experiment != "" {
+			q = q.Where("a.experiment = ?", experiment)
+		}
+		return q
+	}
+	var rows []struct {
+		Experiment string
+		Variant    string
+		Kind       string
+		Actions    int64
+		Users      int64
+	}
+	err = actions().
+		Select("a.experiment, a.variant, a.kind, COUNT(*) AS actions, COUNT(DISTINCT a.user_id) AS users").
+		Group("a.experiment, a.variant, a.kind").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("query experiment actions failed: %w", err)
+	}
+	var converted []struct {
+		Experiment string
+		Variant    string
+		Users      int64
+	}
+	err = actions().
+		Where("a.kind IN ?", []string{model.ExperimentLike, model.ExperimentRating}).
+		Select("a.experiment, a.variant, COUNT(DISTINCT a.user_id) AS users").
+		Group("a.experiment, a.variant").
+		Scan(&converted).Error
+	if err != nil {
+		return nil, fmt.Errorf("query experiment conversions failed: %w", err)
+	}
+
+	index := make(map[[2]string]*ExperimentStats, len(stats))
+	for i := range stats {
+		index[[2]string{stats[i].Experiment, stats[i].Variant}] = &stats[i]
+	}
+	for _, r := range rows {
+		s := index[[2]string{r.Experiment, r.Variant}]
+		if s == nil {
+			continue
+		}
+		switch r.Kind {
+		case model.ExperimentVisit:
+			s.Visits = r.Actions
+		case model.ExperimentLike:
+			s.Likes = r.Actions
+		case model.ExperimentRating:
+			s.Ratings = r.Actions
+		}
+	}
+	for _, r := range converted {
+		if s := index[[2]string{r.Experiment, r.Variant}]; s != nil {
+			s.Converted = r.Users
+		}
+	}
+	for i := range stats {
+		s := &stats[i]
+		if s.Exposures > 0 {
+			s.CTR = float64(s.Visits) / float64(s.Exposures)
+		}
+		if s.Users > 0 {
+			s.Conversion = float64(s.Converted) / float64(s.Users)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Experiment != stats[j].Experiment {
+			return stats[i].Experiment < stats[j].Experiment
+		}
+		return stats[i].Variant < stats[j].Variant
+	})
+	return stats, nil
+}
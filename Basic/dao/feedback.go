@@ -0,0 +1,153 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// NotInterestedItem 不感兴趣记录及对象名称，对象已删除时 Name 为空
+type NotInterestedItem struct {
+	model.NotInterested
+	Name string `json:"name"`
+}
+
+// AddNotInterested 标记不感兴趣，对象不存在时返回对应的 NotFound 错误。
+// 同一对象重复标记时更新有效期
+func AddNotInterested(ctx context.Context, n *model.NotInterested) error {
+	var table string
+	var notFound error
+	switch n.Kind {
+	case model.NotInterestedDish:
+		table, notFound = "dishes", model.ErrDishNotFound
+	case model.NotInterestedStore:
+		table, notFound = "stores", model.ErrStoreNotFound
+	case model.NotInterestedTag:
+		table, notFound = "tags", model.ErrTagNotFound
+	default:
+		return fmt.Errorf("unknown not interested kind %q", n.Kind)
+	}
+	var count int64
+	if err := DB.WithContext(ctx).Table(table).Where("id = ?", n.TargetID).Count(&count).Error; err != nil {
+		return fmt.Errorf("query %s failed: %w", table, err)
+	}
+	if count == 0 {
+		return notFound
+	}
+	err := DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}, {Name: "target_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at", "created_at"}),
+	}).Create(n).Error
+	if err != nil {
+		return fmt.Errorf("save not interested failed: %w", err)
+	}
+	return nil
+}
+
+// RemoveNotInterested 撤销不感兴趣标记，没有标记时不报错
+func RemoveNotInterested(ctx context.Context, uid uint, kind string, targetID uint) error {
+	err := DB.WithContext(ctx).
+		Where("user_id = ? AND kind = ? AND target_id = ?", uid, kind, targetID).
+		Delete(&model.NotInterested{}).Error
+	if err != nil {
+		return fmt.Errorf("delete not interested failed: %w", err)
+	}
+	return nil
+}
+
+// ListNotInterested 返回用户仍然有效的不感兴趣标记，最近的在前
+func ListNotInterested(ctx context.Context, uid uint) ([]NotInterestedItem, error) {
+	var items []NotInterestedItem
+	err := DB.WithContext(ctx).Table("not_interested n").
+		Select("n.*, COALESCE(d.name, s.name, t.name, '') AS name").
+		Joins("LEFT JOIN dishes d ON n.kind = ? AND d.id = n.target_id", model.NotInterestedDish).
+		Joins("LEFT JOIN stores s ON n.kind = ? AND s.id = n.target_id", model.NotInterestedStore).
+		Joins("LEFT JOIN tags t ON n.kind = ? AND t.id = n.target_id", model.NotInterestedTag).
+		Where("n.user_id = ? AND (n.expires_at IS NULL OR n.expires_at > ?)", uid, time.Now()).
+		Order("n.created_at DESC, n.id DESC").
+		Scan(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("query not interested failed: %w", err)
+	}
+	return items, nil
+}
+
+// TagID 按名称查找标签 ID
+func TagID(ctx context.Context, name string) (uint, error) {
+	var tag model.Tag
+	err := DB.WithContext(ctx).Select("id").Where("name = ?", name).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, model.ErrTagNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("query tag failed: %w", err)
+	}
+	return tag.ID, nil
+}
+
+// VisibleDishIDs 返回 ids 中没有被用户标记为不感兴趣（菜品本身、所在店铺或任一标签）的菜品
+func VisibleDishIDs(ctx context.Context, uid uint, ids []uint) (map[uint]bool, error) {
+	visible := make(map[uint]bool, len(ids))
+	if len(ids) == 0 {
+		return visible, nil
+	}
+	var kept []uint
+	err := DB.WithContext(ctx).Table("dishes d").
+		Where("d.id IN ?", ids).
+		Scopes(excludeNotInterested(ctx, uid)).
+		Pluck("d.id", &kept).Error
+	if err != nil {
+		return nil, fmt.Errorf("filter not interested dishes failed: %w", err)
+	}
+	for _, id := range kept {
+		visible[id] = true
+	}
+	return visible, nil
+}
+
+// AllDismissedDishes 返回所有仍然有效的菜品不感兴趣标记，作为协同过滤的负反馈
+func AllDismissedDishes(ctx context.Context) ([]EventSignal, error) {
+	return dismissedDishes(DB.WithContext(ctx))
+}
+
+// UserDismissedDishes 返回用户仍然有效的菜品不感兴趣标记
+func UserDismissedDishes(ctx context.Context, uid uint) ([]EventSignal, error) {
+	return dismissedDishes(DB.WithContext(ctx).Where("user_id = ?", uid))
+}
+
+func dismissedDishes(tx *gorm.DB) ([]EventSignal, error) {
+	var signals []EventSignal
+	err := tx.Model(&model.NotInterested{}).
+		Select("user_id, target_id AS dish_id, 1 AS dismisses").
+		Where("kind = ? AND (expires_at IS NULL OR expires_at > ?)", model.NotInterestedDish, time.Now()).
+		Scan(&signals).Error
+	if err != nil {
+		return nil, fmt.Errorf("query dismissed dishes failed: %w", err)
+	}
+	return signals, nil
+}
+
+// excludeNotInterested 排除用户标记为不感兴趣的菜品、店铺和标签，查询中 dishes 表的别名须为 d。
+// uid 为 0（匿名）时不过滤
+func excludeNotInterested(ctx context.Context, uid uint) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if uid == 0 {
+			return tx
+		}
+		now := time.Now()
+		// targets 每次新建子查询，避免条件互相累加
+		targets := func(kind string) *gorm.DB {
+			return DB.WithContext(ctx).Model(&model.NotInterested{}).
+				Select("target_id").
+				Where("user_id = ? AND kind = ? AND (expires_at IS NULL OR expires_at > ?)", uid, kind, now)
+		}
+		return tx.
+			Where("d.id NOT IN (?)", targets(model.NotInterestedDish)).
+			Where("d.store_id NOT IN (?)", targets(model.NotInterestedStore)).
+			Where("NOT EXISTS (SELECT 1 FROM dishes_tags dt WHERE dt.dishes_id = d.id AND dt.tag_id IN (?))", targets(model.NotInterestedTag))
+	}
+}
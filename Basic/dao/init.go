@@ -1,37 +1,28 @@
 package dao
 
 import (
-	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
 	"context"
 	"fmt"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
 	"time"
 )
 
-var (
-	DB *gorm.DB
-	// 连接池配置参数
-	maxOpenConns    = 200              // 最大打开连接数（根据服务器配置调整）
-	maxIdleConns    = 50               // 最大空闲连接数
-	connMaxLifetime = 10 * time.Minute // 连接最大存活时间
-)
+var DB *gorm.DB
 
-func InitDB() *gorm.DB {
-	dsn := "root:2132047479@tcp(127.0.0.1:3306)/food?charset=utf8mb4&parseTime=True&loc=Local&timeout=10s"
+func InitDB(cfg config.Database) *gorm.DB {
+	d, err := NewDialect(cfg.Driver)
+	if err != nil {
+		panic(&InitError{Msg: "数据库驱动不支持", Err: err})
+	}
+	dialect = d
 
 	// 配置数据库连接池
-	db, err := gorm.Open(mysql.New(mysql.Config{
-		DSN: dsn,
-		// 高并发场景关键配置
-		DefaultStringSize:         256,  // 字符串字段默认长度
-		DisableDatetimePrecision:  true, // 禁用 datetime 精度（提升性能）
-		DontSupportRenameColumn:   true, // 禁止重命名列（避免潜在锁问题）
-		DontSupportRenameIndex:    true, // 禁止重命名索引
-		SkipInitializeWithVersion: false,
-	}), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Warn),
+	db, err := gorm.Open(dialect.Open(cfg.DSN), &gorm.Config{
+		Logger:         slogLogger{},
+		TranslateError: true, // 唯一键冲突等驱动错误转换为 gorm 通用错误
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -46,41 +37,46 @@ func InitDB() *gorm.DB {
 		panic(&InitError{Msg: "获取数据库连接池失败", Err: err})
 	}
 
-	// 配置连接池参数
-	sqlDB.SetMaxOpenConns(maxOpenConns)
-	sqlDB.SetMaxIdleConns(maxIdleConns)
-	sqlDB.SetConnMaxLifetime(connMaxLifetime)
+	// 配置连接池参数（最大打开连接数根据服务器配置调整）
+	maxOpen := cfg.MaxOpenConns
+	if limit := dialect.MaxOpenConns(); limit > 0 && limit < maxOpen {
+		maxOpen = limit
+	}
+	sqlDB.SetMaxOpenConns(maxOpen)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	if err := sqlDB.Ping(); err != nil {
 		panic(&InitError{Msg: "数据库连接健康检查失败", Err: err})
 	}
+	if err := utils.RegisterDBStats(sqlDB, cfg.Driver); err != nil {
+		panic(&InitError{Msg: "注册连接池指标失败", Err: err})
+	}
+	// 每条 SQL 生成一个 span，不记录参数值；连接池指标已由 Prometheus 采集
+	if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics(), tracing.WithoutQueryVariables())); err != nil {
+		panic(&InitError{Msg: "注册链路追踪插件失败", Err: err})
+	}
 
 	DB = db
-	go runAutoMigrate()
 	return DB
 }
 
-// 异步执行数据库迁移（不阻塞主流程）
-func runAutoMigrate() {
-	// 使用带超时的上下文
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	err := DB.WithContext(ctx).AutoMigrate(
-		&model.User{},
-		&model.Search{},
-		&model.Merchant{},
-		&model.Store{},
-		&model.Dishes{},
-		&model.Tag{},
-		&model.History{},
-		&model.Rating{},
-		&model.Like{},
-	)
+// Ping 检查数据库连接是否可用，供就绪探针使用
+func Ping(ctx context.Context) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
 
+// Close 关闭连接池，进程退出前调用
+func Close() error {
+	sqlDB, err := DB.DB()
 	if err != nil {
-		panic(&InitError{Msg: "数据库迁移失败", Err: err})
+		return err
 	}
+	return sqlDB.Close()
 }
 
 type InitError struct {
@@ -0,0 +1,31 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"fmt"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// AcquireLease 尝试获取或续期租约，租约空闲、已过期或本来就由 holder 持有时成功。
+// 获取和续期都是单条条件写入，多个实例同时竞争时只有一个成功
+func AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	db := DB.WithContext(ctx)
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.Lease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)}).Error; err != nil {
+		return false, fmt.Errorf("create lease failed: %w", err)
+	}
+	if err := db.Model(&model.Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now).
+		Updates(map[string]interface{}{"holder": holder, "expires_at": now.Add(ttl)}).Error; err != nil {
+		return false, fmt.Errorf("renew lease failed: %w", err)
+	}
+	// MySQL 在值未变化时不计入影响行数，因此回读持有者判断是否成功
+	var lease model.Lease
+	if err := db.Where("name = ?", name).First(&lease).Error; err != nil {
+		return false, fmt.Errorf("query lease failed: %w", err)
+	}
+	return lease.Holder == holder, nil
+}
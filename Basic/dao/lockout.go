@@ -0,0 +1,86 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// LoginLockRemaining 返回账号剩余锁定时间，未锁定时返回 0
+func LoginLockRemaining(ctx context.Context, role utils.Role, account string) (time.Duration, error) {
+	var records []model.LoginFailure
+	if err := DB.WithContext(ctx).Where("role = ? AND account = ?", role, account).
+		Limit(1).Find(&records).Error; err != nil {
+		return 0, fmt.Errorf("query login failures failed: %w", err)
+	}
+	if len(records) == 0 || records[0].LockedUntil == nil {
+		return 0, nil
+	}
+	if remaining := time.Until(*records[0].LockedUntil); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，返回本次失败后的锁定时长（未锁定为 0）。
+// 失败次数达到阈值后开始锁定，之后每多失败一次锁定时间翻倍
+func RecordLoginFailure(ctx context.Context, role utils.Role, account string, policy config.Lockout) (time.Duration, error) {
+	var lock time.Duration
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginFailure{Role: string(role), Account: account}).Error; err != nil {
+			return err
+		}
+		var record model.LoginFailure
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ? AND account = ?", role, account).First(&record).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if policy.ResetAfter > 0 && !record.LastFailedAt.IsZero() && now.Sub(record.LastFailedAt) > policy.ResetAfter {
+			record.Failures = 0
+		}
+		record.Failures++
+		record.LastFailedAt = now
+		record.LockedUntil = nil
+		if record.Failures >= policy.Threshold {
+			lock = lockDuration(record.Failures-policy.Threshold, policy)
+			until := now.Add(lock)
+			record.LockedUntil = &until
+		}
+		return tx.Model(&model.LoginFailure{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+			"failures":       record.Failures,
+			"last_failed_at": record.LastFailedAt,
+			"locked_until":   record.LockedUntil,
+		}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("record login failure failed: %w", err)
+	}
+	return lock, nil
+}
+
+// ResetLoginFailures 登录成功后清除失败记录
+func ResetLoginFailures(ctx context.Context, role utils.Role, account string) error {
+	if err := DB.WithContext(ctx).Where("role = ? AND account = ?", role, account).
+		Delete(&model.LoginFailure{}).Error; err != nil {
+		return fmt.Errorf("reset login failures failed: %w", err)
+	}
+	return nil
+}
+
+func lockDuration(extra int, policy config.Lockout) time.Duration {
+	d := policy.BaseDuration
+	for i := 0; i < extra && d < policy.MaxDuration; i++ {
+		d *= 2
+	}
+	if d > policy.MaxDuration {
+		d = policy.MaxDuration
+	}
+	return d
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log/slog"
+	"time"
+)
+
+// slowQueryThreshold 超过该耗时的 SQL 以 warn 级别记录
+const slowQueryThreshold = 200 * time.Millisecond
+
+// slogLogger 把 GORM 日志写入 slog，ctx 中的请求 ID 随之输出。
+// SQL 只记录占位符形式，不含参数值，避免密码哈希、手机号等写入日志
+type slogLogger struct{}
+
+func (l slogLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+func (slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// ParamsFilter 实现 gorm.ParamsFilter，丢弃参数后 Trace 得到的是未展开的 SQL
+func (slogLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "sql error", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds(), "err", err)
+	case elapsed > slowQueryThreshold:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow sql", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	case slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "sql", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	}
+}
@@ -7,38 +7,48 @@ import (
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
-	"log"
+	"log/slog"
 )
 
 func MerchantCreate(ctx context.Context, m model.Merchant) error {
 	var count int64
-	if err := DB.WithContext(ctx).Model(&model.Merchant{}).
+	if err := conn(ctx).Model(&model.Merchant{}).
 		Where("merchant_name = ?", m.MerchantName).Count(&count).Error; err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
 
 	if count > 0 {
-		return errors.New("merchant_name already exists")
+		return model.ErrMerchantTaken
+	}
+	password, err := utils.HashPassword(m.Password)
+	if err != nil {
+		return fmt.Errorf("hash password failed: %w", err)
 	}
-	password, _ := utils.Crypto(m.Password)
 	m.Password = password
-	if err := DB.Create(&m).Error; err != nil {
-		log.Println("Create Merchant Error!")
-		return err
+	if err := conn(ctx).Create(&m).Error; err != nil {
+		return fmt.Errorf("create merchant failed: %w", err)
 	}
-	log.Println("Create Merchant Success!")
+	slog.InfoContext(ctx, "merchant created", "merchant_id", m.ID)
 	return nil
 }
 func CheckLogin(ctx context.Context, m model.Merchant) (model.Merchant, error) {
-	inputPass, _ := utils.Crypto(m.Password)
-	if err := DB.WithContext(ctx).Where("merchant_name = ? AND password = ?", m.MerchantName, inputPass).First(&m).Error; err != nil {
-		log.Println("Password is incorrect!")
-		return m, errors.New("password is incorrect")
+	password := m.Password
+	if err := DB.WithContext(ctx).Where("merchant_name = ?", m.MerchantName).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return m, ErrBadCredentials
+		}
+		return m, fmt.Errorf("database error: %w", err)
+	}
+	ok, needsRehash := utils.VerifyPassword(m.Password, password)
+	if !ok {
+		return m, ErrBadCredentials
+	}
+	if needsRehash {
+		upgradePassword(ctx, &model.Merchant{}, m.ID, password)
 	}
-	fmt.Println(m)
 	return m, nil
 }
-func GetProfile(ctx context.Context, id string) (model.Merchant, error) {
+func GetProfile(ctx context.Context, id uint) (model.Merchant, error) {
 	// 查询商家信息并预加载关联的店铺
 	var merchant model.Merchant
 	result := DB.WithContext(ctx).
@@ -47,7 +57,7 @@ func GetProfile(ctx context.Context, id string) (model.Merchant, error) {
 		First(&merchant)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return model.Merchant{}, fmt.Errorf("merchant not found")
+			return model.Merchant{}, model.ErrMerchantNotFound
 		}
 		return model.Merchant{}, fmt.Errorf("database error: %w", result.Error)
 	}
@@ -58,7 +68,7 @@ func UpdateProfile(ctx context.Context, m model.Merchant) error {
 	var existingMerchant model.Merchant
 	if err := DB.WithContext(ctx).First(&existingMerchant, m.ID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("merchant not found")
+			return model.ErrMerchantNotFound
 		}
 		return fmt.Errorf("database error: %w", err)
 	}
@@ -71,7 +81,7 @@ func UpdateProfile(ctx context.Context, m model.Merchant) error {
 			return fmt.Errorf("database error: %w", err)
 		}
 		if count > 0 {
-			return errors.New("merchant name already exists")
+			return model.ErrMerchantTaken
 		}
 	}
 	if m.Phone != existingMerchant.Phone {
@@ -80,7 +90,7 @@ func UpdateProfile(ctx context.Context, m model.Merchant) error {
 			return fmt.Errorf("database error: %w", err)
 		}
 		if count > 0 {
-			return errors.New("phone number already exists")
+			return model.ErrPhoneTaken
 		}
 	}
 	updateFields := map[string]interface{}{}
@@ -100,7 +110,7 @@ func UpdateProfile(ctx context.Context, m model.Merchant) error {
 		return fmt.Errorf("update failed: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("merchant information has been modified, please refresh and try again")
+		return model.VersionConflict("merchant information has been modified, please refresh and try again")
 	}
 	return nil
 }
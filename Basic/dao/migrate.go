@@ -0,0 +1,206 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"io"
+	"sort"
+	"time"
+)
+
+// Migration 一个数据库迁移版本。Up/Down 在事务中执行，
+// 但 MySQL 的 DDL 会隐式提交，迁移失败时可能需要人工清理
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// MigrationStatus 迁移及其应用时间，未应用时 AppliedAt 为 nil
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// ErrPendingMigrations 关闭自动迁移时数据库结构落后于代码
+var ErrPendingMigrations = errors.New("database has pending migrations, run `migrate up` first")
+
+// EnsureSchema 启动时同步执行未应用的迁移，auto 为 false 时只读检查，存在未应用的迁移即返回错误
+func EnsureSchema(ctx context.Context, auto bool) error {
+	if auto {
+		_, err := MigrateUp(ctx, false, io.Discard)
+		return err
+	}
+	pending, err := PendingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w (next: %d_%s)", ErrPendingMigrations, pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+// MigrationStatuses 返回所有迁移的应用状态，按版本号排序
+func MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range sortedMigrations() {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if r, ok := applied[m.Version]; ok {
+			at := r.AppliedAt
+			s.AppliedAt = &at
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// PendingMigrations 返回尚未应用的迁移
+func PendingMigrations(ctx context.Context) ([]Migration, error) {
+	applied, err := appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range sortedMigrations() {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// MigrateUp 按版本号依次执行未应用的迁移，返回执行的数量。
+// dryRun 时不修改数据库，只输出各迁移将执行的 SQL：原生 SQL 写入 out，
+// Migrator 生成的 DDL 由 GORM 直接打印到标准输出（依赖表结构探测的步骤按当前库生成）
+func MigrateUp(ctx context.Context, dryRun bool, out io.Writer) (int, error) {
+	pending, err := PendingMigrations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) > 0 && !dryRun {
+		if err := DB.WithContext(ctx).AutoMigrate(&model.SchemaMigration{}); err != nil {
+			return 0, fmt.Errorf("create schema_migrations failed: %w", err)
+		}
+	}
+	for i, m := range pending {
+		fmt.Fprintf(out, "up %d_%s\n", m.Version, m.Name)
+		if err := runMigration(ctx, m, m.Up, dryRun, out, func(tx *gorm.DB) error {
+			return tx.Create(&model.SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		}); err != nil {
+			return i, fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+	return len(pending), nil
+}
+
+// MigrateDown 回滚最近应用的 steps 个迁移，返回回滚的数量
+func MigrateDown(ctx context.Context, steps int, dryRun bool, out io.Writer) (int, error) {
+	applied, err := appliedMigrations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	all := sortedMigrations()
+	done := 0
+	for i := len(all) - 1; i >= 0 && done < steps; i-- {
+		m := all[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("migration %d_%s is irreversible", m.Version, m.Name)
+		}
+		fmt.Fprintf(out, "down %d_%s\n", m.Version, m.Name)
+		if err := runMigration(ctx, m, m.Down, dryRun, out, func(tx *gorm.DB) error {
+			return tx.Delete(&model.SchemaMigration{}, m.Version).Error
+		}); err != nil {
+			return done, fmt.Errorf("rollback %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		done++
+	}
+	return done, nil
+}
+
+func runMigration(ctx context.Context, m Migration, step func(tx *gorm.DB) error, dryRun bool, out io.Writer, record func(tx *gorm.DB) error) error {
+	if dryRun {
+		// 演练模式的会话不记录日志，原生 SQL 由 execAll 经上下文中的 out 写出
+		ctx = context.WithValue(ctx, dryRunOutputKey{}, out)
+		tx := DB.WithContext(ctx).Session(&gorm.Session{DryRun: true, Logger: logger.Discard})
+		return step(tx)
+	}
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := step(tx); err != nil {
+			return err
+		}
+		return record(tx)
+	})
+}
+
+// appliedMigrations 只读查询已应用的迁移，schema_migrations 不存在时视为没有应用任何迁移
+func appliedMigrations(ctx context.Context) (map[int64]model.SchemaMigration, error) {
+	if !DB.WithContext(ctx).Migrator().HasTable(&model.SchemaMigration{}) {
+		return map[int64]model.SchemaMigration{}, nil
+	}
+	var records []model.SchemaMigration
+	if err := DB.WithContext(ctx).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("query schema_migrations failed: %w", err)
+	}
+	applied := make(map[int64]model.SchemaMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+func sortedMigrations() []Migration {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+// dryRunOutputKey 演练模式下原生 SQL 的输出，存放在迁移会话的上下文中
+type dryRunOutputKey struct{}
+
+// execAll 依次执行原生 SQL，用于 AutoMigrate 无法表达的索引等结构；演练模式下只写出语句
+func execAll(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		out, dryRun := tx.Statement.Context.Value(dryRunOutputKey{}).(io.Writer)
+		for _, stmt := range statements {
+			if dryRun {
+				fmt.Fprintf(out, "%s;\n", stmt)
+				continue
+			}
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type tableIndex struct {
+	table interface{}
+	name  string
+}
+
+// dropIndexes 删除索引，由 Migrator 处理不同数据库 DROP INDEX 语法的差异
+func dropIndexes(indexes ...tableIndex) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, idx := range indexes {
+			if err := tx.Migrator().DropIndex(idx.table, idx.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
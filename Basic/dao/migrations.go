@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/dao/schema"
+	"gorm.io/gorm"
+)
+
+// migrations 按版本号递增排列，已发布的迁移不能修改，结构变更需要追加新版本。
+// 表结构使用 schema 包中的快照，不引用 model，避免 model 的修改改变已发布迁移的含义
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		// 与引入迁移前的 AutoMigrate 一致，已有数据库执行时只会补齐缺失的表和列
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&schema.User{},
+				&schema.Search{},
+				&schema.Merchant{},
+				&schema.Store{},
+				&schema.Dishes{},
+				&schema.Tag{},
+				&schema.History{},
+				&schema.Rating{},
+				&schema.Like{},
+				&schema.RefreshToken{},
+				&schema.RevokedToken{},
+				&schema.VerificationCode{},
+				&schema.LoginFailure{},
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(
+				&schema.LoginFailure{},
+				&schema.VerificationCode{},
+				&schema.RevokedToken{},
+				&schema.RefreshToken{},
+				&schema.Like{},
+				&schema.Rating{},
+				&schema.History{},
+				"dishes_tags",
+				&schema.Tag{},
+				&schema.Dishes{},
+				&schema.Store{},
+				&schema.Merchant{},
+				&schema.Search{},
+				&schema.User{},
+			)
+		},
+	},
+	{
+		Version: 2,
+		Name:    "composite_indexes",
+		// 覆盖按用户查询点赞/评分/浏览/搜索记录以及按店铺查询菜品的常用路径
+		Up: execAll(
+			"CREATE INDEX idx_likes_user_dish ON likes (user_id, dish_id)",
+			"CREATE INDEX idx_ratings_user_dish ON ratings (user_id, dish_id)",
+			"CREATE INDEX idx_histories_user_created ON histories (user_id, created_at DESC)",
+			"CREATE INDEX idx_searches_user_created ON searches (user_id, created_at DESC)",
+			"CREATE INDEX idx_dishes_store_available ON dishes (store_id, available)",
+			"CREATE INDEX idx_refresh_tokens_device ON refresh_tokens (role, subject_id, device_id)",
+		),
+		Down: dropIndexes(
+			tableIndex{&schema.Like{}, "idx_likes_user_dish"},
+			tableIndex{&schema.Rating{}, "idx_ratings_user_dish"},
+			tableIndex{&schema.History{}, "idx_histories_user_created"},
+			tableIndex{&schema.Search{}, "idx_searches_user_created"},
+			tableIndex{&schema.Dishes{}, "idx_dishes_store_available"},
+			tableIndex{&schema.RefreshToken{}, "idx_refresh_tokens_device"},
+		),
+	},
+	{
+		Version: 3,
+		Name:    "dish_similarity",
+		// 推荐服务离线计算的菜品近邻表，按批次写入后整体切换
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&schema.SimilarityVersion{}, &schema.DishSimilarity{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&schema.DishSimilarity{}, &schema.SimilarityVersion{})
+		},
+	},
+	{
+		Version: 4,
+		Name:    "experiment_events",
+		// 在线实验的曝光和行为记录，用于按分组统计点击率和转化率
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&schema.ExperimentEvent{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&schema.ExperimentEvent{})
+		},
+	},
+	{
+		Version: 5,
+		Name:    "user_events",
+		// 推荐结果的曝光、点击、停留和不感兴趣事件，只追加
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&schema.UserEvent{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&schema.UserEvent{})
+		},
+	},
+	{
+		Version: 6,
+		Name:    "not_interested",
+		// 用户标记的不感兴趣菜品、店铺和标签，推荐和搜索时过滤
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&schema.NotInterested{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&schema.NotInterested{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "leases",
+		// 多实例部署时选出执行定时任务（如推荐模型重算）的实例
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&schema.Lease{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&schema.Lease{})
+		},
+	},
+}
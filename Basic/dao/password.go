@@ -0,0 +1,75 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/utils"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// ErrBadCredentials 账号不存在或密码错误，两种情况不加区分以免泄露账号是否存在
+var ErrBadCredentials = model.Unauthorized("bad_credentials", "account or password is incorrect")
+
+// upgradePassword 登录成功后把旧版 AES 密文替换为 bcrypt 哈希。
+// 升级失败不影响本次登录，下次登录会重试
+func upgradePassword(ctx context.Context, table interface{}, id uint, password string) {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		slog.WarnContext(ctx, "upgrade password hash failed", "id", id, "err", err)
+		return
+	}
+	if err := DB.WithContext(ctx).Model(table).Where("id = ?", id).
+		UpdateColumn("password", hash).Error; err != nil {
+		slog.WarnContext(ctx, "upgrade password hash failed", "id", id, "err", err)
+	}
+}
+
+// accountTable 按主体类型返回账号表模型
+func accountTable(role utils.Role) (interface{}, error) {
+	switch role {
+	case utils.RoleUser:
+		return &model.User{}, nil
+	case utils.RoleMerchant:
+		return &model.Merchant{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported account role: %s", role)
+	}
+}
+
+// PhoneRegistered 判断手机号是否已注册对应类型的账号
+func PhoneRegistered(ctx context.Context, role utils.Role, phone string) (bool, error) {
+	table, err := accountTable(role)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	if err := DB.WithContext(ctx).Model(table).Where("phone = ?", phone).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ResetPassword 通过手机号重置密码，并注销该账号的所有会话
+func ResetPassword(ctx context.Context, role utils.Role, phone, password string) error {
+	table, err := accountTable(role)
+	if err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("hash password failed: %w", err)
+	}
+	var ids []uint
+	if err := conn(ctx).Model(table).Where("phone = ?", phone).Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if len(ids) == 0 {
+		return model.NotFound("account_not_found", "account not found")
+	}
+	if err := conn(ctx).Model(table).Where("id = ?", ids[0]).
+		UpdateColumn("password", hash).Error; err != nil {
+		return fmt.Errorf("reset password failed: %w", err)
+	}
+	return RevokeAllSessions(ctx, role, ids[0])
+}
@@ -0,0 +1,155 @@
+// Package schema 数据库迁移使用的表结构快照，与对应迁移版本发布时的 model 一致。
+// 迁移不直接使用 model 中的结构体，model 之后的修改不会改变已发布迁移的含义；
+// 已发布的快照不能修改，结构变更需要追加迁移，类型重名时加版本后缀。
+// 快照只保留字段、标签和关联，不包含钩子和业务方法
+package schema
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// v1 baseline：引入版本化迁移前启动时 AutoMigrate 的结构
+
+type User struct {
+	ID        uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	Username  string `gorm:"unique;not null;type:varchar(64);index"`
+	Password  string `gorm:"not null;type:varchar(64)"`
+	Phone     string `gorm:"not null;type:varchar(20);uniqueIndex"`
+	Avatar    string `gorm:"not null;type:varchar(255);default:https://pic.616pic.com/ys_img/00/33/87/E4RE0kQH3V.jpg"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Searches  []Search `gorm:"foreignKey:UserID"`
+}
+
+type Search struct {
+	ID        uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID"`
+	Key       string `gorm:"unique;not null;type:varchar(64);index"`
+	CreatedAt time.Time
+}
+
+type Merchant struct {
+	ID           uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	MerchantName string `gorm:"unique;not null;type:varchar(64);index"`
+	Password     string `gorm:"not null;type:varchar(64)"`
+	Avatar       string `gorm:"not null;type:varchar(255);default:https://pro.upload.logomaker.com.cn/2019/12/08/CyGRIoPAmuhh.jpg"`
+	Phone        string `gorm:"not null;type:varchar(20);uniqueIndex"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Version      uint    `gorm:"version;default:1"`
+	Stores       []Store `gorm:"foreignKey:MerchantID"`
+}
+
+type Store struct {
+	ID          uint    `gorm:"primary_key;AUTO_INCREMENT"`
+	MerchantID  uint    `gorm:"not null;index"`
+	Name        string  `gorm:"not null;type:varchar(32);index:,unique,where:merchant_id = merchant_id"`
+	Description string  `gorm:"type:varchar(255)"`
+	Active      bool    `gorm:"default:true"`
+	AvgRating   float64 `gorm:"default:0"`
+	Address     string  `gorm:"type:varchar(64)"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     uint `gorm:"version;default:1"`
+	Merchant    Merchant
+	Dishes      []Dishes `gorm:"foreignKey:StoreID"`
+}
+
+type Dishes struct {
+	ID        uint            `gorm:"primary_key;AUTO_INCREMENT"`
+	StoreID   uint            `gorm:"not null;index"`
+	Name      string          `gorm:"not null;type:varchar(32)"`
+	Price     decimal.Decimal `gorm:"type:decimal(10,2)"`
+	Desc      string          `gorm:"type:varchar(255)"`
+	ImageURL  string          `gorm:"type:varchar(255)"`
+	Available bool            `gorm:"default:true"`
+	AvgRating float64         `gorm:"default:0"`
+	LikeNum   uint            `gorm:"default:0"`
+	RatingSum uint            `gorm:"default:0"`
+	RatingNum uint            `gorm:"default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   uint  `gorm:"version;default:1"`
+	Store     Store `gorm:"foreignKey:StoreID"`
+	Tags      []Tag `gorm:"many2many:dishes_tags;"`
+}
+
+type Tag struct {
+	ID     uint     `gorm:"primary_key;AUTO_INCREMENT"`
+	Name   string   `gorm:"not null;type:varchar(12);uniqueIndex"`
+	Dishes []Dishes `gorm:"many2many:dishes_tags;"`
+}
+
+type History struct {
+	ID        uint  `gorm:"primary_key;AUTO_INCREMENT"`
+	UserID    uint  `gorm:"not null;index"`
+	User      User  `gorm:"foreignKey:UserID"`
+	StoreID   uint  `gorm:"not null;index"`
+	Store     Store `gorm:"foreignKey:StoreID"`
+	CreatedAt time.Time
+}
+
+type Rating struct {
+	ID        uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	Comment   string `gorm:"type:varchar(128)"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID"`
+	DishID    uint   `gorm:"not null;index"`
+	Dishes    Dishes `gorm:"foreignKey:DishID"`
+	Num       uint   `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+type Like struct {
+	ID        uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID"`
+	DishID    uint   `gorm:"not null;index"`
+	Dishes    Dishes `gorm:"foreignKey:DishID"`
+	CreatedAt time.Time
+}
+
+type RefreshToken struct {
+	ID              uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	JTI             string `gorm:"type:varchar(32);not null;uniqueIndex"`
+	FamilyID        string `gorm:"type:varchar(32);not null;index"`
+	Role            string `gorm:"type:varchar(16);not null;index:idx_refresh_subject"`
+	SubjectID       uint   `gorm:"not null;index:idx_refresh_subject"`
+	DeviceID        string `gorm:"type:varchar(64)"`
+	UserAgent       string `gorm:"type:varchar(255)"`
+	AccessJTI       string `gorm:"type:varchar(32)"`
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time `gorm:"index"`
+	RevokedAt       *time.Time
+	ReplacedBy      string `gorm:"type:varchar(32)"`
+	CreatedAt       time.Time
+}
+
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;type:varchar(32)"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+type VerificationCode struct {
+	ID         uint      `gorm:"primary_key;AUTO_INCREMENT"`
+	Target     string    `gorm:"type:varchar(64);not null;index:idx_code_target"`
+	Purpose    string    `gorm:"type:varchar(32);not null;index:idx_code_target"`
+	CodeHash   string    `gorm:"type:varchar(64);not null"`
+	Attempts   int       `gorm:"not null;default:0"`
+	ExpiresAt  time.Time `gorm:"index"`
+	ConsumedAt *time.Time
+	CreatedAt  time.Time
+}
+
+type LoginFailure struct {
+	ID           uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	Role         string `gorm:"type:varchar(16);not null;uniqueIndex:idx_login_failure_account"`
+	Account      string `gorm:"type:varchar(64);not null;uniqueIndex:idx_login_failure_account"`
+	Failures     int    `gorm:"not null;default:0"`
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,30 @@
+package schema
+
+import "time"
+
+// v3 dish_similarity
+
+type SimilarityVersion struct {
+	Version    int64     `gorm:"primaryKey"`
+	Similarity string    `gorm:"type:varchar(32);not null"`
+	Neighbors  int       `gorm:"not null"`
+	Dishes     int       `gorm:"not null"`
+	Entries    int       `gorm:"not null"`
+	Active     bool      `gorm:"not null;default:false;index"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+func (SimilarityVersion) TableName() string {
+	return "similarity_versions"
+}
+
+type DishSimilarity struct {
+	Version    int64   `gorm:"primaryKey;autoIncrement:false"`
+	DishID     uint    `gorm:"primaryKey;autoIncrement:false"`
+	NeighborID uint    `gorm:"primaryKey;autoIncrement:false"`
+	Sim        float64 `gorm:"not null"`
+}
+
+func (DishSimilarity) TableName() string {
+	return "dish_similarity"
+}
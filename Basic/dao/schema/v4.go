@@ -0,0 +1,21 @@
+package schema
+
+import "time"
+
+// v4 experiment_events
+
+type ExperimentEvent struct {
+	ID         uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	Experiment string `gorm:"type:varchar(64);not null;index:idx_experiment_events_user,priority:1"`
+	Variant    string `gorm:"type:varchar(64);not null"`
+	UserID     uint   `gorm:"not null;index:idx_experiment_events_user,priority:2"`
+	Kind       string `gorm:"type:varchar(16);not null"`
+	DishID     uint
+	StoreID    uint
+	Items      int
+	CreatedAt  time.Time `gorm:"not null;index:idx_experiment_events_user,priority:3"`
+}
+
+func (ExperimentEvent) TableName() string {
+	return "experiment_events"
+}
@@ -0,0 +1,20 @@
+package schema
+
+import "time"
+
+// v5 user_events
+
+type UserEvent struct {
+	ID        uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	UserID    uint   `gorm:"not null;index:idx_user_events_user_created,priority:1"`
+	RequestID string `gorm:"type:varchar(64);not null;default:'';index"`
+	Type      string `gorm:"type:varchar(16);not null"`
+	DishID    uint   `gorm:"not null"`
+	Position  int
+	DwellMs   int
+	CreatedAt time.Time `gorm:"not null;index:idx_user_events_user_created,priority:2"`
+}
+
+func (UserEvent) TableName() string {
+	return "user_events"
+}
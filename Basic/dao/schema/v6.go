@@ -0,0 +1,18 @@
+package schema
+
+import "time"
+
+// v6 not_interested
+
+type NotInterested struct {
+	ID        uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_not_interested_target,priority:1"`
+	Kind      string `gorm:"type:varchar(8);not null;uniqueIndex:idx_not_interested_target,priority:2"`
+	TargetID  uint   `gorm:"not null;uniqueIndex:idx_not_interested_target,priority:3"`
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
+
+func (NotInterested) TableName() string {
+	return "not_interested"
+}
@@ -0,0 +1,11 @@
+package schema
+
+import "time"
+
+// v7 leases
+
+type Lease struct {
+	Name      string    `gorm:"primaryKey;type:varchar(64)"`
+	Holder    string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
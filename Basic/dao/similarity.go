@@ -0,0 +1,93 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// similarityBatchSize 近邻表每批插入的行数
+const similarityBatchSize = 1000
+
+// ActiveSimilarityVersion 返回当前生效的近邻表批次，没有时返回 nil
+func ActiveSimilarityVersion(ctx context.Context) (*model.SimilarityVersion, error) {
+	var v model.SimilarityVersion
+	err := DB.WithContext(ctx).Where("active = ?", true).Order("version DESC").First(&v).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query similarity version failed: %w", err)
+	}
+	return &v, nil
+}
+
+// DishSimilarities 返回某个批次的全部近邻，按菜品和相似度从高到低排列
+func DishSimilarities(ctx context.Context, version int64) ([]model.DishSimilarity, error) {
+	var rows []model.DishSimilarity
+	err := DB.WithContext(ctx).
+		Where("version = ?", version).
+		Order("dish_id, sim DESC, neighbor_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("query dish similarity failed: %w", err)
+	}
+	return rows, nil
+}
+
+// SaveDishSimilarity 把近邻写入新批次，写完后在一个事务里锁住批次表并切换为生效批次，读取方不会看到写了一半的数据。
+// 上一个批次保留给正在加载它的实例，更早的批次和中断留下的残余一并清理。
+// 如果并发的任务已经切换到更新的批次，本批次不再生效
+func SaveDishSimilarity(ctx context.Context, meta *model.SimilarityVersion, rows []model.DishSimilarity) error {
+	meta.Version = 0
+	meta.Active = false
+	if err := DB.WithContext(ctx).Create(meta).Error; err != nil {
+		return fmt.Errorf("create similarity version failed: %w", err)
+	}
+	for i := range rows {
+		rows[i].Version = meta.Version
+	}
+	if len(rows) > 0 {
+		if err := DB.WithContext(ctx).CreateInBatches(rows, similarityBatchSize).Error; err != nil {
+			return fmt.Errorf("insert dish similarity failed: %w", err)
+		}
+	}
+
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住全部批次行，并发的切换在这里排队，后执行的一方能看到先提交的结果
+		var versions []model.SimilarityVersion
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("version", "active").Order("version").Find(&versions).Error
+		if err != nil {
+			return fmt.Errorf("lock similarity versions failed: %w", err)
+		}
+		var prev int64
+		for _, v := range versions {
+			if v.Active {
+				prev = max(prev, v.Version)
+			}
+		}
+		if prev > meta.Version {
+			return nil
+		}
+		// 一条语句同时停用旧批次和启用本批次，不会出现没有或多个生效批次的中间状态
+		if err := tx.Model(&model.SimilarityVersion{}).Where("1 = 1").
+			Update("active", gorm.Expr("version = ?", meta.Version)).Error; err != nil {
+			return fmt.Errorf("activate similarity version failed: %w", err)
+		}
+		meta.Active = true
+		if prev == 0 {
+			return nil
+		}
+		if err := tx.Where("version < ?", prev).Delete(&model.DishSimilarity{}).Error; err != nil {
+			return fmt.Errorf("purge dish similarity failed: %w", err)
+		}
+		if err := tx.Where("version < ?", prev).Delete(&model.SimilarityVersion{}).Error; err != nil {
+			return fmt.Errorf("purge similarity version failed: %w", err)
+		}
+		return nil
+	})
+}
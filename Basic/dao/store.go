@@ -21,7 +21,7 @@ func CreateStore(ctx context.Context, store model.Store) error {
 	if err := tx.Model(&model.Merchant{}).Where("id = ?", store.MerchantID).First(&merchant).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			tx.Rollback()
-			return fmt.Errorf("merchant not found: %w", err)
+			return model.ErrMerchantNotFound
 		}
 		tx.Rollback()
 		return fmt.Errorf("database error: %w", err)
@@ -36,7 +36,7 @@ func CreateStore(ctx context.Context, store model.Store) error {
 	}
 	if count > 0 {
 		tx.Rollback()
-		return errors.New("store name already exists under this merchant")
+		return model.ErrStoreNameTaken
 	}
 	// 3. 创建店铺记录
 	if err := tx.Create(&store).Error; err != nil {
@@ -59,7 +59,7 @@ func MyStore(ctx context.Context, merchantID uint) ([]model.Store, error) {
 		return nil, fmt.Errorf("database error: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return nil, gorm.ErrRecordNotFound
+		return nil, model.ErrStoreNotFound
 	}
 	return stores, nil
 }
@@ -74,8 +74,8 @@ func SearchStore(ctx context.Context, sid uint) (model.Store, error) {
 		First(&store)
 
 	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return model.Store{}, result.Error
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.Store{}, model.ErrStoreNotFound
 		}
 		return model.Store{}, fmt.Errorf("database error: %w", result.Error)
 	}
@@ -84,14 +84,14 @@ func SearchStore(ctx context.Context, sid uint) (model.Store, error) {
 }
 func UpdateStore(ctx context.Context, store model.Store) error {
 	if store.ID == 0 || store.MerchantID == 0 {
-		return errors.New("store ID and merchant ID are required")
+		return model.InvalidField("storeId", "required", "")
 	}
 
 	var originalStore model.Store
 	result := DB.WithContext(ctx).Where("id = ? AND merchant_id = ?", store.ID, store.MerchantID).First(&originalStore)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("store not found or unauthorized")
+			return model.ErrStoreNotFound
 		}
 		return fmt.Errorf("database query failed: %w", result.Error)
 	}
@@ -115,7 +115,7 @@ func UpdateStore(ctx context.Context, store model.Store) error {
 		return fmt.Errorf("update failed: %w", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("store information has been modified, please refresh and try again") // 版本冲突
+		return model.VersionConflict("store information has been modified, please refresh and try again")
 	}
 	return nil
 }
@@ -135,7 +135,7 @@ func DeleteStore(ctx context.Context, sid uint, mid uint) error {
 	if result.Error != nil {
 		tx.Rollback()
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("store not found or unauthorized")
+			return model.ErrStoreNotFound
 		}
 		return fmt.Errorf("query store failed: %w", result.Error)
 	}
@@ -144,7 +144,7 @@ func DeleteStore(ctx context.Context, sid uint, mid uint) error {
 	tx.Model(&model.Dishes{}).Where("store_id = ?", sid).Count(&dishCount)
 	if dishCount > 0 {
 		tx.Rollback()
-		return errors.New("cannot delete store with associated dishes")
+		return model.Conflict("store_has_dishes", "cannot delete store with associated dishes")
 	}
 	// 3. 执行删除操作（物理删除，因为前提是店铺无菜品，软删除意义不大反而浪费）
 	result = tx.Unscoped().Delete(&store)
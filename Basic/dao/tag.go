@@ -11,7 +11,7 @@ import (
 
 func AddTags(ctx context.Context, tags []string) error {
 	if len(tags) == 0 {
-		return errors.New("tags list is empty")
+		return model.InvalidField("tags", "required", "")
 	}
 	tx := DB.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -62,10 +62,10 @@ func GetTags(ctx context.Context) ([]string, error) {
 }
 func ChooseTag(ctx context.Context, dishID uint, tags []string) error {
 	if dishID == 0 {
-		return errors.New("dish ID is required")
+		return model.InvalidField("dishId", "required", "")
 	}
 	if len(tags) > 3 {
-		return errors.New("maximum 3 tags allowed")
+		return model.InvalidField("tags", "max", "3")
 	}
 	tx := DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
@@ -93,7 +93,10 @@ func ChooseTag(ctx context.Context, dishID uint, tags []string) error {
 	}
 	var dish model.Dishes
 	if err := tx.First(&dish, dishID).Error; err != nil {
-		return fmt.Errorf("dish not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ErrDishNotFound
+		}
+		return fmt.Errorf("query dish failed: %w", err)
 	}
 	if err := tx.Model(&dish).Association("Tags").Clear(); err != nil {
 		return fmt.Errorf("clear tags failed: %w", err)
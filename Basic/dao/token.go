@@ -0,0 +1,255 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/utils"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTokenInvalid = model.Unauthorized("refresh_token_invalid", "refresh token is invalid or expired")
+	ErrTokenReused  = model.Unauthorized("refresh_token_reused", "refresh token reuse detected, session revoked")
+)
+
+// IssueTokens 登录成功后创建新会话并签发令牌对。
+// 同一主体在同一设备上只保留一个会话，旧会话会被吊销
+func IssueTokens(ctx context.Context, role utils.Role, subjectID uint, deviceID, userAgent string) (utils.TokenPair, error) {
+	session := utils.NewTokenID()
+	pair, err := utils.GenToken(role, subjectID, session)
+	if err != nil {
+		return utils.TokenPair{}, fmt.Errorf("generate token failed: %w", err)
+	}
+	err = DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if deviceID != "" {
+			var families []string
+			if err := tx.Model(&model.RefreshToken{}).
+				Where("role = ? AND subject_id = ? AND device_id = ? AND revoked_at IS NULL", role, subjectID, deviceID).
+				Distinct().Pluck("family_id", &families).Error; err != nil {
+				return err
+			}
+			for _, family := range families {
+				if err := revokeFamily(tx, family); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Create(newRefreshRecord(pair, session, role, subjectID, deviceID, userAgent)).Error
+	})
+	if err != nil {
+		return utils.TokenPair{}, fmt.Errorf("create session failed: %w", err)
+	}
+	return pair, nil
+}
+
+// RotateRefreshToken 用 refresh_token 换取新的令牌对，旧令牌立即失效。
+// 已被轮换过的令牌再次出现说明令牌泄露，整个会话会被吊销
+func RotateRefreshToken(ctx context.Context, role utils.Role, rToken, userAgent string) (utils.TokenPair, error) {
+	claims, err := utils.ParseRefreshToken(rToken)
+	if err != nil {
+		return utils.TokenPair{}, ErrTokenInvalid
+	}
+	if tokenRole, _, err := claims.Principal(); err != nil || tokenRole != role {
+		return utils.TokenPair{}, ErrTokenInvalid
+	}
+
+	var pair utils.TokenPair
+	reused := false
+	err = DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current model.RefreshToken
+		if err := tx.Where("jti = ?", claims.Id).First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTokenInvalid
+			}
+			return err
+		}
+		if current.RevokedAt != nil {
+			if current.ReplacedBy != "" {
+				reused = true
+				return ErrTokenReused
+			}
+			return ErrTokenInvalid
+		}
+		if time.Now().After(current.ExpiresAt) {
+			return ErrTokenInvalid
+		}
+
+		pair, err = utils.GenToken(role, current.SubjectID, current.FamilyID)
+		if err != nil {
+			return fmt.Errorf("generate token failed: %w", err)
+		}
+		// 条件更新保证并发刷新时只有一个请求能轮换成功
+		now := time.Now()
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{"revoked_at": now, "replaced_by": pair.RJTI})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return ErrTokenReused
+		}
+		if err := denyAccessToken(tx, current.AccessJTI, current.AccessExpiresAt); err != nil {
+			return err
+		}
+		return tx.Create(newRefreshRecord(pair, current.FamilyID, role, current.SubjectID, current.DeviceID, userAgent)).Error
+	})
+	if reused {
+		if err := RevokeSession(ctx, claims.Session); err != nil {
+			slog.ErrorContext(ctx, "revoke reused session failed", "role", role, "err", err)
+		}
+		return utils.TokenPair{}, ErrTokenReused
+	}
+	if err != nil {
+		if errors.Is(err, ErrTokenInvalid) {
+			return utils.TokenPair{}, err
+		}
+		return utils.TokenPair{}, fmt.Errorf("rotate refresh token failed: %w", err)
+	}
+	return pair, nil
+}
+
+// RevokeSession 吊销会话下所有刷新令牌，并拉黑仍在有效期内的 access_token
+func RevokeSession(ctx context.Context, sessionID string) error {
+	if err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return revokeFamily(tx, sessionID)
+	}); err != nil {
+		return fmt.Errorf("revoke session failed: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllSessions 吊销主体在所有设备上的会话
+func RevokeAllSessions(ctx context.Context, role utils.Role, subjectID uint) error {
+	err := conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var families []string
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("role = ? AND subject_id = ? AND revoked_at IS NULL", role, subjectID).
+			Distinct().Pluck("family_id", &families).Error; err != nil {
+			return err
+		}
+		for _, family := range families {
+			if err := revokeFamily(tx, family); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("revoke sessions failed: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredTokens 清理已过期的刷新令牌和拒绝名单记录
+func PurgeExpiredTokens(ctx context.Context) error {
+	now := time.Now()
+	if err := DB.WithContext(ctx).Where("expires_at < ?", now).Delete(&model.RefreshToken{}).Error; err != nil {
+		return fmt.Errorf("purge refresh tokens failed: %w", err)
+	}
+	if err := DB.WithContext(ctx).Where("expires_at < ?", now).Delete(&model.RevokedToken{}).Error; err != nil {
+		return fmt.Errorf("purge revoked tokens failed: %w", err)
+	}
+	return nil
+}
+
+func newRefreshRecord(pair utils.TokenPair, session string, role utils.Role, subjectID uint, deviceID, userAgent string) *model.RefreshToken {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	if len(deviceID) > 64 {
+		deviceID = deviceID[:64]
+	}
+	return &model.RefreshToken{
+		JTI:             pair.RJTI,
+		FamilyID:        session,
+		Role:            string(role),
+		SubjectID:       subjectID,
+		DeviceID:        deviceID,
+		UserAgent:       userAgent,
+		AccessJTI:       pair.AJTI,
+		AccessExpiresAt: pair.AExpiresAt,
+		ExpiresAt:       pair.RExpiresAt,
+	}
+}
+
+func revokeFamily(tx *gorm.DB, family string) error {
+	var active []model.RefreshToken
+	if err := tx.Where("family_id = ? AND revoked_at IS NULL", family).Find(&active).Error; err != nil {
+		return err
+	}
+	for _, t := range active {
+		if err := denyAccessToken(tx, t.AccessJTI, t.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+	return tx.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now()).Error
+}
+
+func denyAccessToken(tx *gorm.DB, jti string, expiresAt time.Time) error {
+	if jti == "" || time.Now().After(expiresAt) {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+// TokenDenyList 基于 revoked_tokens 表的拒绝名单，命中结果缓存在进程内
+type TokenDenyList struct {
+	mu    sync.RWMutex
+	cache map[string]time.Time
+}
+
+// NewTokenDenyList 返回持久化拒绝名单，供 utils.DenyList 使用
+func NewTokenDenyList() *TokenDenyList {
+	return &TokenDenyList{cache: make(map[string]time.Time)}
+}
+
+func (l *TokenDenyList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	return denyAccessToken(DB.WithContext(ctx), jti, expiresAt)
+}
+
+func (l *TokenDenyList) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	l.mu.RLock()
+	exp, ok := l.cache[jti]
+	l.mu.RUnlock()
+	if ok && time.Now().Before(exp) {
+		return true, nil
+	}
+	var records []model.RevokedToken
+	if err := DB.WithContext(ctx).Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		Limit(1).Find(&records).Error; err != nil {
+		return false, err
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+	l.remember(jti, records[0].ExpiresAt)
+	return true, nil
+}
+
+// maxDenyCache 缓存条目上限，超过后先清理过期条目
+const maxDenyCache = 10000
+
+func (l *TokenDenyList) remember(jti string, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.cache) >= maxDenyCache {
+		now := time.Now()
+		for k, exp := range l.cache {
+			if now.After(exp) {
+				delete(l.cache, k)
+			}
+		}
+	}
+	l.cache[jti] = expiresAt
+}
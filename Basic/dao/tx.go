@@ -0,0 +1,25 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// txKey 在 ctx 中传递进行中的事务，见 withTx
+type txKey struct{}
+
+// withTx 在事务中执行 fn，fn 收到的 ctx 携带该事务，其中通过 conn 执行的 DAO 操作都在同一事务内，
+// fn 返回错误时整体回滚
+func withTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn 返回 ctx 中的事务，没有时返回 DB
+func conn(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return DB.WithContext(ctx)
+}
@@ -6,36 +6,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"gorm.io/gorm"
+	"log/slog"
 	"strings"
 )
 
 func CreateUser(ctx context.Context, u model.User) error {
 	var count int64
-	if err := DB.WithContext(ctx).Model(&model.User{}).
+	if err := conn(ctx).Model(&model.User{}).
 		Where("username = ?", u.Username).Count(&count).Error; err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
 
 	if count > 0 {
-		return errors.New("username already exists")
+		return model.ErrUsernameTaken
+	}
+	password, err := utils.HashPassword(u.Password)
+	if err != nil {
+		return fmt.Errorf("hash password failed: %w", err)
 	}
-	password, _ := utils.Crypto(u.Password)
 	u.Password = password
-	if err := DB.Create(&u).Error; err != nil {
-		log.Println("Create user Error!")
-		return err
+	if err := conn(ctx).Create(&u).Error; err != nil {
+		return fmt.Errorf("create user failed: %w", err)
 	}
-	log.Println("Create user Success!")
+	slog.InfoContext(ctx, "user created", "user_id", u.ID)
 	return nil
 }
 func UserLogin(ctx context.Context, u model.User) (model.User, error) {
-	inputPass, _ := utils.Crypto(u.Password)
-	if err := DB.WithContext(ctx).Where("username = ? AND password = ?", u.Username, inputPass).First(&u).Error; err != nil {
-		log.Println("Password is incorrect!")
-		return u, errors.New("password is incorrect")
+	password := u.Password
+	if err := DB.WithContext(ctx).Where("username = ?", u.Username).First(&u).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return u, ErrBadCredentials
+		}
+		return u, fmt.Errorf("database error: %w", err)
+	}
+	ok, needsRehash := utils.VerifyPassword(u.Password, password)
+	if !ok {
+		return u, ErrBadCredentials
+	}
+	if needsRehash {
+		upgradePassword(ctx, &model.User{}, u.ID, password)
 	}
-	fmt.Println(u)
 	return u, nil
 }
 
@@ -50,19 +61,11 @@ func UserSearch(ctx context.Context, keyword string, uid uint) ([]model.ShowMerc
 		var results []model.ShowMerchant
 		err := DB.WithContext(ctx).
 			Table("dishes d").
-			Select(`
-				d.id AS dishes_id,
-                d.image_url AS img,
-                d.name AS dishes_name,
-				d.like_num AS likenum,
-                s.name AS store_name,
-				s.address AS store_address,
-                FORMAT(d.avg_rating, 1) AS rating,
-                CONCAT("/store/", s.id) AS link
-            `).
+			Select(showMerchantColumns()).
 			Joins("JOIN stores s ON d.store_id = s.id").
-			Order("RAND()"). // 使用数据库的随机排序功能
-			Limit(20).       // 限制返回20条记录
+			Scopes(excludeNotInterested(ctx, uid)).
+			Order(dialect.Random()). // 使用数据库的随机排序功能
+			Limit(20).               // 限制返回20条记录
 			Scan(&results).Error
 
 		if err != nil {
@@ -91,18 +94,10 @@ func UserSearch(ctx context.Context, keyword string, uid uint) ([]model.ShowMerc
 	var results []model.ShowMerchant
 	err := DB.WithContext(ctx).
 		Table("dishes d").
-		Select(`
-            d.id AS dishes_id,
-            d.image_url AS img,
-            d.name AS dishes_name,
-			d.like_num AS likenum,
-            s.name AS store_name,
-			s.address AS store_address,
-            FORMAT(d.avg_rating, 1) AS rating,
-            CONCAT("/store/", s.id) AS link
-        `).
+		Select(showMerchantColumns()).
 		Joins("JOIN stores s ON d.store_id = s.id").
 		Where(whereCondition, args...).
+		Scopes(excludeNotInterested(ctx, uid)).
 		Order(orderCondition).
 		Scan(&results).Error
 
@@ -112,6 +107,20 @@ func UserSearch(ctx context.Context, keyword string, uid uint) ([]model.ShowMerc
 	return results, nil
 }
 
+// showMerchantColumns 搜索结果的查询字段，评分格式化和链接拼接依赖数据库方言
+func showMerchantColumns() string {
+	return `
+        d.id AS dishes_id,
+        d.image_url AS img,
+        d.name AS dishes_name,
+        d.like_num AS likenum,
+        s.name AS store_name,
+        s.address AS store_address,
+        ` + dialect.FormatDecimal("d.avg_rating", 1) + ` AS rating,
+        ` + dialect.Concat("'/store/'", "s.id") + ` AS link
+    `
+}
+
 func buildOrderCondition(keyword string, keywords []string) string {
 	allKeywordsPattern := "%" + strings.Join(keywords, "%") + "%"
 	orderSQL := fmt.Sprintf(`
@@ -141,11 +150,11 @@ func AddHistory(ctx context.Context, uid uint, SID uint) error {
 }
 func AllSearch(ctx context.Context, uid uint) ([]string, error) {
 	var results []string
-	err := DB.Debug().Model(model.Search{}).WithContext(ctx).Select("key").Where("user_id = ?", uid).Order("created_at DESC").Limit(20).Find(&results).Error
+	err := DB.WithContext(ctx).Model(model.Search{}).Select("key").Where("user_id = ?", uid).Order("created_at DESC").Limit(20).Find(&results).Error
 	if err != nil {
 		return nil, fmt.Errorf("get search records failed: %w", err)
 	}
-	return results[:20], nil
+	return results, nil
 }
 func AllLike(ctx context.Context, uid uint) ([]model.Dishes, error) {
 	var results []model.Dishes
@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrCodeInvalid = model.Validation("code_invalid", "verification code is invalid or expired")
+
+// errCodeTooFrequent retryAfter 为距离可以重新申请的剩余时间
+func errCodeTooFrequent(retryAfter time.Duration) error {
+	return model.TooManyRequests("code_too_frequent", "verification code requested too frequently", retryAfter)
+}
+
+// VerificationStore 基于 verification_codes 表的验证码存储
+type VerificationStore struct{}
+
+// Create 保存新验证码，同一目标在 interval 内只能申请一次，旧的未使用验证码会失效
+func (VerificationStore) Create(ctx context.Context, target, purpose, codeHash string, ttl, interval time.Duration) error {
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var recent []model.VerificationCode
+		if err := tx.Where("target = ? AND purpose = ? AND created_at > ?", target, purpose, time.Now().Add(-interval)).
+			Order("created_at DESC").Limit(1).Find(&recent).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if len(recent) > 0 {
+			return errCodeTooFrequent(max(time.Until(recent[0].CreatedAt.Add(interval)), time.Second))
+		}
+		now := time.Now()
+		if err := tx.Model(&model.VerificationCode{}).
+			Where("target = ? AND purpose = ? AND consumed_at IS NULL", target, purpose).
+			Update("consumed_at", now).Error; err != nil {
+			return fmt.Errorf("expire old codes failed: %w", err)
+		}
+		return tx.Create(&model.VerificationCode{
+			Target:    target,
+			Purpose:   purpose,
+			CodeHash:  codeHash,
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+}
+
+// Delete 删除最近一次申请的验证码，用于发送失败时回滚，避免占用重发间隔
+func (VerificationStore) Delete(ctx context.Context, target, purpose string) error {
+	return DB.WithContext(ctx).
+		Where("target = ? AND purpose = ? AND consumed_at IS NULL", target, purpose).
+		Delete(&model.VerificationCode{}).Error
+}
+
+// Verify 校验验证码，失败次数达到 maxAttempts 后验证码作废；校验通过时不使验证码失效，由 Consume 作废
+func (VerificationStore) Verify(ctx context.Context, target, purpose, codeHash string, maxAttempts int) (uint, error) {
+	var code model.VerificationCode
+	result := DB.WithContext(ctx).
+		Where("target = ? AND purpose = ? AND consumed_at IS NULL AND expires_at > ?", target, purpose, time.Now()).
+		Order("id DESC").Limit(1).Find(&code)
+	if result.Error != nil {
+		return 0, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 || code.Attempts >= maxAttempts {
+		return 0, ErrCodeInvalid
+	}
+	if code.CodeHash != codeHash {
+		if err := DB.WithContext(ctx).Model(&model.VerificationCode{}).Where("id = ?", code.ID).
+			Update("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+			return 0, fmt.Errorf("database error: %w", err)
+		}
+		return 0, ErrCodeInvalid
+	}
+	return code.ID, nil
+}
+
+// Consume 在一个事务中作废验证码并执行 fn，fn 失败时回滚，验证码仍可使用。
+// 作废是带 consumed_at IS NULL 条件的更新，并发请求中只有一个能更新到该行，其余返回 ErrCodeInvalid，
+// 因此同一验证码只会执行一次 fn
+func (VerificationStore) Consume(ctx context.Context, id uint, fn func(ctx context.Context) error) error {
+	return withTx(ctx, func(ctx context.Context) error {
+		result := conn(ctx).Model(&model.VerificationCode{}).
+			Where("id = ? AND consumed_at IS NULL", id).
+			Update("consumed_at", time.Now())
+		if result.Error != nil {
+			return fmt.Errorf("database error: %w", result.Error)
+		}
+		if result.RowsAffected != 1 {
+			return ErrCodeInvalid
+		}
+		return fn(ctx)
+	})
+}
@@ -1,12 +1,143 @@
 package main
 
 import (
+	"Food_recommendation/Basic/controller"
 	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/recommender"
 	"Food_recommendation/Basic/router"
+	"Food_recommendation/Basic/verify"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	dao.InitDB()
-	r := router.InitRouter()
-	r.Run(":6001")
+	configPath := flag.String("config", "", "path to config file (default $FOOD_CONFIG or config.yaml)")
+	flag.Parse()
+
+	cfg := config.MustLoad(*configPath)
+	utils.InitLogger(cfg.Log)
+	shutdownTracing, err := utils.InitTracing(context.Background(), cfg.Tracing, utils.ServiceAPI)
+	if err != nil {
+		fatal("init tracing failed", err)
+	}
+	utils.InitJWT(cfg.JWT)
+	if flag.Arg(0) == "token" {
+		utils.ExitAfterFlush(runToken(flag.Args()[1:]), shutdownTracing)
+	}
+	utils.InitCrypto(cfg.Crypto)
+	utils.InitI18n(cfg.I18n)
+	dao.InitDB(cfg.Database)
+	if flag.Arg(0) == "migrate" {
+		utils.ExitAfterFlush(runMigrate(flag.Args()[1:]), shutdownTracing)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	err = dao.EnsureSchema(ctx, cfg.Database.AutoMigrate)
+	cancel()
+	if err != nil {
+		fatal("database schema not ready", err)
+	}
+	utils.DenyList = dao.NewTokenDenyList()
+	notifier, err := utils.NewNotifier(cfg.Notifier)
+	if err != nil {
+		fatal("init notifier failed", err)
+	}
+	controller.InitVerify(verify.NewService(dao.VerificationStore{}, notifier, cfg.Verify))
+	controller.InitLockout(cfg.Lockout)
+	controller.InitExperiments(cfg.Experiments)
+	rc, err := recommender.NewClient(cfg.Recommend, recommender.DishStoreFunc(dao.PopularDishes))
+	if err != nil {
+		fatal("init recommend client failed", err)
+	}
+	defer rc.Close()
+	controller.InitRecommend(rc)
+	store, err := utils.NewLimitStore(cfg.RateLimit)
+	if err != nil {
+		fatal("init rate limit store failed", err)
+	}
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go purgeTokens(sigCtx)
+
+	r := router.InitRouter(utils.NewRateLimiter(store, cfg.RateLimit.Rules))
+	srv := &http.Server{Addr: cfg.Server.Addr, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("listen failed", err)
+		}
+	}()
+	metrics := serveMetrics(cfg.Server.MetricsAddr)
+	slog.Info("api server started", "addr", cfg.Server.Addr, "metrics_addr", cfg.Server.MetricsAddr)
+
+	<-sigCtx.Done()
+	stop()
+	// 先让就绪探针失败，等待 drain_delay 让负载均衡摘除实例（期间仍正常处理请求），
+	// 再停止接收新连接并等待进行中的请求（含未提交的事务）完成
+	slog.Info("shutting down, draining in-flight requests", "drain_delay", cfg.Server.DrainDelay.String(), "timeout", cfg.Server.ShutdownTimeout.String())
+	controller.SetDraining()
+	time.Sleep(cfg.Server.DrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("graceful shutdown failed", "err", err)
+	}
+	if metrics != nil {
+		if err := metrics.Shutdown(shutdownCtx); err != nil {
+			slog.Error("metrics server shutdown failed", "err", err)
+		}
+	}
+	if err := dao.Close(); err != nil {
+		slog.Error("close database failed", "err", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("flush traces failed", "err", err)
+	}
+	slog.Info("server exited")
+}
+
+// serveMetrics 在内部地址单独暴露 Prometheus 指标，addr 为空时不启动
+func serveMetrics(addr string) *http.Server {
+	if addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", utils.MetricsHandler())
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("metrics listen failed", err)
+		}
+	}()
+	return srv
+}
+
+// purgeTokens 定期清理过期的刷新令牌和拒绝名单，ctx 取消后退出
+func purgeTokens(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dao.PurgeExpiredTokens(ctx); err != nil {
+				slog.ErrorContext(ctx, "purge expired tokens failed", "err", err)
+			}
+		}
+	}
+}
+
+// fatal 记录错误并退出进程
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
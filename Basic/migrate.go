@@ -0,0 +1,81 @@
+package main
+
+import (
+	"Food_recommendation/Basic/dao"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+const migrateUsage = `usage: basic [-config path] migrate <command> [flags]
+
+commands:
+  up       apply all pending migrations
+  down     roll back the most recent migrations (-steps, default 1)
+  status   list migrations and whether they have been applied
+
+flags:
+  -dry-run print the SQL that would be executed without changing the database
+`
+
+// runMigrate 执行 migrate 子命令，返回进程退出码
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print SQL without executing it")
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		n, err := dao.MigrateUp(ctx, *dryRun, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if *dryRun {
+			fmt.Printf("%d migration(s) planned\n", n)
+		} else {
+			fmt.Printf("%d migration(s) applied\n", n)
+		}
+	case "down":
+		n, err := dao.MigrateDown(ctx, *steps, *dryRun, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if *dryRun {
+			fmt.Printf("%d migration(s) planned\n", n)
+		} else {
+			fmt.Printf("%d migration(s) rolled back\n", n)
+		}
+	case "status":
+		statuses, err := dao.MigrationStatuses(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
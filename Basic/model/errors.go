@@ -0,0 +1,144 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 错误类别，错误渲染中间件按类别映射 HTTP 状态码
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrVersionConflict = errors.New("version conflict")
+	ErrValidation      = errors.New("validation failed")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrUnavailable     = errors.New("service unavailable")
+)
+
+// Error 带稳定错误码的领域错误。Code 供客户端识别，Message 为默认说明，
+// Err 为底层原因，只写入日志不返回给客户端
+type Error struct {
+	Kind       error
+	Code       string
+	Message    string
+	Details    []FieldError
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// Wrap 返回附带底层原因的副本，原错误可作为哨兵变量复用
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+func NotFound(code, msg string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: msg}
+}
+
+func Conflict(code, msg string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: msg}
+}
+
+func VersionConflict(msg string) *Error {
+	return &Error{Kind: ErrVersionConflict, Code: "version_conflict", Message: msg}
+}
+
+func Validation(code, msg string) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: msg}
+}
+
+func Unauthorized(code, msg string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: msg}
+}
+
+func Forbidden(code, msg string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: msg}
+}
+
+func TooManyRequests(code, msg string, retryAfter time.Duration) *Error {
+	return &Error{Kind: ErrTooManyRequests, Code: code, Message: msg, RetryAfter: retryAfter}
+}
+
+func Unavailable(code, msg string) *Error {
+	return &Error{Kind: ErrUnavailable, Code: code, Message: msg}
+}
+
+// FieldError 单个字段的校验失败信息，Rule 与 validator 标签保持一致（required、min、max、oneof 等）
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// InvalidFields 由字段校验结果构造校验错误
+func InvalidFields(fields ...FieldError) *Error {
+	msg := "validation failed"
+	for i := range fields {
+		if fields[i].Message == "" {
+			fields[i].Message = FieldMessage(fields[i].Field, fields[i].Rule, fields[i].Param)
+		}
+	}
+	if len(fields) > 0 {
+		msg = fields[0].Message
+	}
+	return &Error{Kind: ErrValidation, Code: "validation_failed", Message: msg, Details: fields}
+}
+
+// InvalidField 单个字段校验失败
+func InvalidField(field, rule, param string) *Error {
+	return InvalidFields(FieldError{Field: field, Rule: rule, Param: param})
+}
+
+// FieldMessage 返回字段校验规则的默认英文说明
+func FieldMessage(field, rule, param string) string {
+	switch rule {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min":
+		return fmt.Sprintf("length of %s must be at least %s", field, param)
+	case "max":
+		return fmt.Sprintf("length of %s must be at most %s", field, param)
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, param)
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, param)
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// 模型校验和数据访问层共用的错误
+var (
+	ErrUserNotFound     = NotFound("user_not_found", "user not found")
+	ErrMerchantNotFound = NotFound("merchant_not_found", "merchant not found")
+	ErrStoreNotFound    = NotFound("store_not_found", "store not found or inactive")
+	ErrDishNotFound     = NotFound("dish_not_found", "dish not found")
+	ErrTagNotFound      = NotFound("tag_not_found", "tag not found")
+	ErrPhoneTaken       = Conflict("phone_taken", "phone number already registered")
+	ErrUsernameTaken    = Conflict("username_taken", "username already exists")
+	ErrMerchantTaken    = Conflict("merchant_name_taken", "merchant name already exists")
+	ErrStoreNameTaken   = Conflict("store_name_taken", "store name already exists under this merchant")
+	ErrDishNameTaken    = Conflict("dish_name_taken", "dish name already exists in this store")
+)
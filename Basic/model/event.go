@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// 用户对推荐结果的隐式反馈类型
+const (
+	EventImpression = "impression"
+	EventClick      = "click"
+	EventDwell      = "dwell"
+	EventDismiss    = "dismiss"
+)
+
+// UserEvent 用户对推荐结果中某个菜品的一次隐式反馈，只追加不修改。
+// RequestID 为返回该推荐的 /api/user/recommend 请求 ID
+type UserEvent struct {
+	ID        uint      `gorm:"primary_key;AUTO_INCREMENT"`
+	UserID    uint      `gorm:"not null;index:idx_user_events_user_created,priority:1"`
+	RequestID string    `gorm:"type:varchar(64);not null;default:'';index"`
+	Type      string    `gorm:"type:varchar(16);not null"`
+	DishID    uint      `gorm:"not null"`
+	Position  int       // 菜品在推荐列表中的位置，从 0 开始
+	DwellMs   int       // dwell 事件的停留毫秒数
+	CreatedAt time.Time `gorm:"not null;index:idx_user_events_user_created,priority:2"`
+}
+
+func (UserEvent) TableName() string {
+	return "user_events"
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// 实验事件类型，曝光由推荐接口记录，其余为曝光后的用户行为
+const (
+	ExperimentExposure = "exposure"
+	ExperimentLike     = "like"
+	ExperimentRating   = "rating"
+	ExperimentVisit    = "visit"
+)
+
+// ExperimentEvent 实验中的一次曝光或行为，Experiment、Variant 为发生时用户所在的实验分组
+type ExperimentEvent struct {
+	ID         uint      `gorm:"primary_key;AUTO_INCREMENT"`
+	Experiment string    `gorm:"type:varchar(64);not null;index:idx_experiment_events_user,priority:1"`
+	Variant    string    `gorm:"type:varchar(64);not null"`
+	UserID     uint      `gorm:"not null;index:idx_experiment_events_user,priority:2"`
+	Kind       string    `gorm:"type:varchar(16);not null"`
+	DishID     uint      // 点赞、评分的菜品
+	StoreID    uint      // 浏览的店铺
+	Items      int       // 曝光的菜品数
+	CreatedAt  time.Time `gorm:"not null;index:idx_experiment_events_user,priority:3"`
+}
+
+func (ExperimentEvent) TableName() string {
+	return "experiment_events"
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// 不感兴趣的对象类型
+const (
+	NotInterestedDish  = "dish"
+	NotInterestedStore = "store"
+	NotInterestedTag   = "tag"
+)
+
+// NotInterested 用户标记的不感兴趣：不再向其推荐或搜索出某个菜品、某个店铺的菜品或带某个标签的菜品。
+// TargetID 按 Kind 为菜品、店铺或标签 ID，ExpiresAt 为空时长期有效
+type NotInterested struct {
+	ID        uint       `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	UserID    uint       `gorm:"not null;uniqueIndex:idx_not_interested_target,priority:1" json:"-"`
+	Kind      string     `gorm:"type:varchar(8);not null;uniqueIndex:idx_not_interested_target,priority:2" json:"kind"`
+	TargetID  uint       `gorm:"not null;uniqueIndex:idx_not_interested_target,priority:3" json:"targetId"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (NotInterested) TableName() string {
+	return "not_interested"
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Lease 多实例之间的租约，同一时刻只有 Holder 可以执行 Name 对应的任务
+type Lease struct {
+	Name      string    `gorm:"primaryKey;type:varchar(64)"`
+	Holder    string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// LoginFailure 账号连续登录失败记录，用于暴力破解锁定
+type LoginFailure struct {
+	ID           uint   `gorm:"primary_key;AUTO_INCREMENT"`
+	Role         string `gorm:"type:varchar(16);not null;uniqueIndex:idx_login_failure_account"`
+	Account      string `gorm:"type:varchar(64);not null;uniqueIndex:idx_login_failure_account"`
+	Failures     int    `gorm:"not null;default:0"`
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+	UpdatedAt    time.Time
+}
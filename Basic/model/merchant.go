@@ -43,28 +43,28 @@ var phoneRegex = regexp.MustCompile(`^1[3-9]\d{9}$`)
 func (m *Merchant) BeforeCreate(tx *gorm.DB) error {
 	m.ID = uint(GenID())
 	if !phoneRegex.MatchString(m.Phone) {
-		return errors.New("手机号格式不正确")
+		return InvalidField("Phone", "phone", "")
 	}
 	m.MerchantName = strings.TrimSpace(m.MerchantName)
 	if m.MerchantName == "" {
-		return errors.New("商户名不能为空")
+		return InvalidField("MerchantName", "required", "")
 	}
 	if len(m.MerchantName) > 64 {
-		return errors.New("商户名长度不能超过64个字符")
+		return InvalidField("MerchantName", "max", "64")
 	}
 	if len(m.Password) < 8 {
-		return errors.New("密码长度必须至少8位")
+		return InvalidField("Password", "min", "8")
 	}
 	var existingMerchant Merchant
 	if err := tx.Where("phone = ?", m.Phone).First(&existingMerchant).Error; err == nil {
-		return errors.New("该手机号已被注册")
+		return ErrPhoneTaken
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	if err := tx.Where("merchant_name = ?", m.MerchantName).First(&existingMerchant).Error; err == nil {
-		return errors.New("该商户名已被使用")
+		return ErrMerchantTaken
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	return nil
 }
@@ -86,20 +86,20 @@ type Store struct {
 
 func (s *Store) BeforeCreate(tx *gorm.DB) error {
 	if s.Name == "" {
-		return errors.New("门店名称不能为空")
+		return InvalidField("name", "required", "")
 	}
 	if len(s.Name) > 32 {
-		return errors.New("门店名称长度不能超过32个字符")
+		return InvalidField("name", "max", "32")
 	}
 	if len(s.Description) > 255 {
-		return errors.New("门店描述长度不能超过255个字符")
+		return InvalidField("description", "max", "255")
 	}
 	var count int64
 	if err := tx.Model(&Merchant{}).Where("id = ?", s.MerchantID).Count(&count).Error; err != nil {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	if count == 0 {
-		return errors.New("关联的商户不存在")
+		return ErrMerchantNotFound
 	}
 	return nil
 }
@@ -125,35 +125,35 @@ type Dishes struct {
 
 func (d *Dishes) BeforeCreate(tx *gorm.DB) error {
 	if d.Name == "" {
-		return errors.New("菜品名称不能为空")
+		return InvalidField("name", "required", "")
 	}
 	if len(d.Name) > 32 {
-		return errors.New("菜品名称长度不能超过32个字符")
+		return InvalidField("name", "max", "32")
 	}
 	if len(d.Desc) > 255 {
-		return errors.New("菜品描述长度不能超过255个字符")
+		return InvalidField("desc", "max", "255")
 	}
 	if d.Price.IsNegative() {
-		return errors.New("菜品价格不能为负数")
+		return InvalidField("price", "gte", "0")
 	}
 	var count int64
 	if err := tx.Model(&Store{}).Where("id = ? AND active = true", d.StoreID).Count(&count).Error; err != nil {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	if count == 0 {
-		return errors.New("关联的店铺不存在或未激活")
+		return ErrStoreNotFound
 	}
 	if err := tx.Model(&Dishes{}).Where("store_id = ? AND name = ?", d.StoreID, d.Name).Count(&count).Error; err != nil {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	if count > 0 {
-		return errors.New("同一店铺下不能有同名菜品")
+		return ErrDishNameTaken
 	}
 	if d.AvgRating < 0 || d.AvgRating > 5 {
-		return errors.New("评分必须在0到5之间")
+		return InvalidField("avgRating", "lte", "5")
 	}
 	if d.ImageURL != "" && !isValidURL(d.ImageURL) {
-		return errors.New("图片URL格式无效")
+		return InvalidField("imageUrl", "url", "")
 	}
 	return nil
 }
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// SchemaMigration 已应用的数据库迁移版本
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(128);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// SimilarityVersion 一次离线近邻计算的批次，同一时刻只有一个批次生效
+type SimilarityVersion struct {
+	Version    int64     `gorm:"primaryKey"`
+	Similarity string    `gorm:"type:varchar(32);not null"`
+	Neighbors  int       `gorm:"not null"` // 每个菜品保留的邻居数
+	Dishes     int       `gorm:"not null"`
+	Entries    int       `gorm:"not null"`
+	Active     bool      `gorm:"not null;default:false;index"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+func (SimilarityVersion) TableName() string {
+	return "similarity_versions"
+}
+
+// DishSimilarity 菜品的一个近邻及相似度，Version 为所属批次
+type DishSimilarity struct {
+	Version    int64   `gorm:"primaryKey;autoIncrement:false"`
+	DishID     uint    `gorm:"primaryKey;autoIncrement:false"`
+	NeighborID uint    `gorm:"primaryKey;autoIncrement:false"`
+	Sim        float64 `gorm:"not null"`
+}
+
+func (DishSimilarity) TableName() string {
+	return "dish_similarity"
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// RefreshToken 服务端保存的刷新令牌，同一次登录轮换出的令牌共享 FamilyID（即一个设备会话）
+type RefreshToken struct {
+	ID              uint       `gorm:"primary_key;AUTO_INCREMENT" json:"id"`
+	JTI             string     `gorm:"type:varchar(32);not null;uniqueIndex" json:"-"`
+	FamilyID        string     `gorm:"type:varchar(32);not null;index" json:"sessionId"`
+	Role            string     `gorm:"type:varchar(16);not null;index:idx_refresh_subject" json:"role"`
+	SubjectID       uint       `gorm:"not null;index:idx_refresh_subject" json:"-"`
+	DeviceID        string     `gorm:"type:varchar(64)" json:"deviceId"`
+	UserAgent       string     `gorm:"type:varchar(255)" json:"userAgent"`
+	AccessJTI       string     `gorm:"type:varchar(32)" json:"-"` // 与本令牌一同签发的 access_token，吊销时一并拉黑
+	AccessExpiresAt time.Time  `json:"-"`
+	ExpiresAt       time.Time  `gorm:"index" json:"expiresAt"`
+	RevokedAt       *time.Time `json:"-"`
+	ReplacedBy      string     `gorm:"type:varchar(32)" json:"-"` // 轮换后的新令牌 JTI，非空表示已被轮换
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+// RevokedToken access_token 拒绝名单，过期后可清理
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;type:varchar(32)"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
@@ -22,28 +22,28 @@ type User struct {
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	u.ID = uint(GenID())
 	if !phoneRegex.MatchString(u.Phone) {
-		return errors.New("手机号格式不正确")
+		return InvalidField("Phone", "phone", "")
 	}
 	u.Username = strings.TrimSpace(u.Username)
 	if u.Username == "" {
-		return errors.New("用户名不能为空")
+		return InvalidField("username", "required", "")
 	}
 	if len(u.Username) > 64 {
-		return errors.New("用户名长度不能超过64个字符")
+		return InvalidField("username", "max", "64")
 	}
 	if len(u.Password) < 8 {
-		return errors.New("密码长度必须至少8位")
+		return InvalidField("Password", "min", "8")
 	}
 	var existingUser User
 	if err := tx.Where("phone = ?", u.Phone).First(&existingUser).Error; err == nil {
-		return errors.New("该手机号已被注册")
+		return ErrPhoneTaken
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	if err := tx.Where("username = ?", u.Username).First(&existingUser).Error; err == nil {
-		return errors.New("该用户名已被使用")
+		return ErrUsernameTaken
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	return nil
 }
@@ -59,24 +59,24 @@ type Search struct {
 func (s *Search) BeforeCreate(tx *gorm.DB) error {
 	s.Key = strings.TrimSpace(s.Key)
 	if s.Key == "" {
-		return errors.New("搜索关键词不能为空")
+		return InvalidField("key", "required", "")
 	}
 	if len(s.Key) > 64 {
-		return errors.New("搜索关键词长度不能超过64个字符")
+		return InvalidField("key", "max", "64")
 	}
 	var count int64
 	if err := tx.Model(&User{}).Where("id = ?", s.UserID).Count(&count).Error; err != nil {
-		return fmt.Errorf("数据库查询错误: %w", err)
+		return fmt.Errorf("database error: %w", err)
 	}
 	if count == 0 {
-		return errors.New("关联的用户不存在")
+		return ErrUserNotFound
 	}
 	if s.ID == 0 {
 		var existingSearch Search
 		if err := tx.Where("`key` = ?", s.Key).First(&existingSearch).Error; err == nil {
-			return errors.New("该搜索关键词已存在")
+			return Conflict("search_key_exists", "search keyword already exists")
 		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("数据库查询错误: %w", err)
+			return fmt.Errorf("database error: %w", err)
 		}
 	}
 	if s.CreatedAt.IsZero() {
@@ -100,13 +100,13 @@ func (h *History) BeforeCreate(tx *gorm.DB) error {
 		return err
 	}
 	if count == 0 {
-		return errors.New("关联的用户不存在")
+		return ErrUserNotFound
 	}
 	if err := tx.Model(&Store{}).Where("id = ? AND active = true", h.StoreID).Count(&count).Error; err != nil {
 		return err
 	}
 	if count == 0 {
-		return errors.New("关联的店铺不存在或未激活")
+		return ErrStoreNotFound
 	}
 	return nil
 }
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// VerificationCode 验证码记录，只保存哈希值
+type VerificationCode struct {
+	ID         uint      `gorm:"primary_key;AUTO_INCREMENT"`
+	Target     string    `gorm:"type:varchar(64);not null;index:idx_code_target"`
+	Purpose    string    `gorm:"type:varchar(32);not null;index:idx_code_target"`
+	CodeHash   string    `gorm:"type:varchar(64);not null"`
+	Attempts   int       `gorm:"not null;default:0"`
+	ExpiresAt  time.Time `gorm:"index"`
+	ConsumedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// ValidPhone 校验手机号格式
+func ValidPhone(phone string) bool {
+	return phoneRegex.MatchString(phone)
+}
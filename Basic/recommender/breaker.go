@@ -0,0 +1,85 @@
+package recommender
+
+import (
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	stateClosed = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// breaker 连续失败计数熔断器：连续失败 threshold 次后打开，
+// cooldown 后进入半开状态只放行一次试探调用，试探成功则关闭，失败则重新打开
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     int
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow 判断本次调用是否放行
+func (b *breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(stateHalfOpen)
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success 记录一次成功调用
+func (b *breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	b.setState(stateClosed)
+}
+
+// Failure 记录一次失败调用
+func (b *breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(stateOpen)
+	}
+}
+
+// Release 放行的调用既不算成功也不算失败（例如调用方取消）时归还试探名额
+func (b *breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) setState(s int) {
+	b.state = s
+	breakerState.Set(float64(s))
+}
@@ -0,0 +1,25 @@
+package recommender
+
+import (
+	"Food_recommendation/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	responses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_client_responses_total",
+		Help:      "Recommendations served by source (the recommend service model, or popular for the local fallback).",
+	}, []string{"source"})
+	fallbackCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_fallback_cache_requests_total",
+		Help:      "Lookups of the cached popular fallback list by result (hit or miss).",
+	}, []string{"result"})
+	breakerState = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_client_breaker_state",
+		Help:      "Circuit breaker state of the recommend client: 0 closed, 1 open, 2 half-open.",
+	})
+)
@@ -0,0 +1,68 @@
+package recommender
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+	"sync"
+	"time"
+)
+
+// DishStore 热门菜品来源，dao.PopularDishes 为数据库实现
+type DishStore interface {
+	PopularDishes(ctx context.Context, limit int) ([]model.Dishes, error)
+}
+
+// DishStoreFunc 把普通函数适配为 DishStore
+type DishStoreFunc func(ctx context.Context, limit int) ([]model.Dishes, error)
+
+func (f DishStoreFunc) PopularDishes(ctx context.Context, limit int) ([]model.Dishes, error) {
+	return f(ctx, limit)
+}
+
+// popularList 本地缓存的热门菜品列表，推荐服务不可用时不会因兜底查询压垮数据库
+type popularList struct {
+	store DishStore
+	size  int
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	dishes    []model.Dishes
+	refreshed time.Time
+}
+
+func newPopularList(store DishStore, size int, ttl time.Duration) *popularList {
+	return &popularList{store: store, size: size, ttl: ttl}
+}
+
+// Page 返回 [from, to) 区间的热门菜品，缓存过期时重新查询，查询失败时沿用旧列表
+func (p *popularList) Page(ctx context.Context, from, to int) ([]model.Dishes, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dishes != nil && time.Since(p.refreshed) <= p.ttl {
+		fallbackCache.WithLabelValues("hit").Inc()
+	} else {
+		fallbackCache.WithLabelValues("miss").Inc()
+		dishes, err := p.store.PopularDishes(ctx, p.size)
+		switch {
+		case err == nil:
+			p.dishes, p.refreshed = dishes, time.Now()
+		case p.dishes == nil:
+			return nil, err
+		}
+	}
+	return page(p.dishes, from, to), nil
+}
+
+// page 按推荐服务相同的规则截取 [from, to) 区间，越界部分截断
+func page[T any](items []T, from, to int) []T {
+	if from < 0 {
+		from = 0
+	}
+	if to > len(items) {
+		to = len(items)
+	}
+	if from >= to {
+		return nil
+	}
+	return items[from:to]
+}
@@ -0,0 +1,177 @@
+package recommender
+
+import (
+	"Food_recommendation/Basic/model"
+	gen "Food_recommendation/Recom/proto/gen"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// 推荐结果来源，推荐服务返回的结果以其采用的模型名（itemcf、als、blend 等）为来源
+const (
+	SourceRecommender = "recommender" // 推荐服务未返回模型名
+	SourcePopular     = "popular"     // 本地热门菜品兜底
+)
+
+// Result 一页推荐结果及其来源，Fallback 表示由本地热门菜品兜底。
+// Experiment、Variant 为推荐服务采用的实验分组，热门兜底时为空
+type Result struct {
+	Items      []*gen.ShowMerchant
+	Source     string
+	Fallback   bool
+	Experiment string
+	Variant    string
+}
+
+// Client 推荐服务的长连接客户端。连接在进程内复用，UNAVAILABLE 由 gRPC 按退避策略重试，
+// 连续失败后熔断，熔断或调用失败时返回热门菜品
+type Client struct {
+	conn     *grpc.ClientConn
+	client   gen.RecommendServiceClient
+	health   healthpb.HealthClient
+	timeout  time.Duration
+	breaker  *breaker
+	fallback *popularList
+}
+
+// NewClient 创建客户端，不会阻塞等待连接建立
+func NewClient(cfg config.Recommend, store DishStore) (*Client, error) {
+	conn, err := grpc.NewClient(cfg.Target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.Keepalive,
+			Timeout:             cfg.Timeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithDefaultServiceConfig(retryServiceConfig(cfg.Retry)),
+		grpc.WithUnaryInterceptor(utils.RequestIDClientInterceptor()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create recommend client: %w", err)
+	}
+	return &Client{
+		conn:     conn,
+		client:   gen.NewRecommendServiceClient(conn),
+		health:   healthpb.NewHealthClient(conn),
+		timeout:  cfg.Timeout,
+		breaker:  newBreaker(cfg.Breaker.Threshold, cfg.Breaker.Cooldown),
+		fallback: newPopularList(store, cfg.Fallback.Size, cfg.Fallback.TTL),
+	}, nil
+}
+
+// retryServiceConfig 生成 gRPC 重试策略，只重试 UNAVAILABLE（请求未到达服务端或服务端正在退出）
+func retryServiceConfig(r config.Retry) string {
+	return fmt.Sprintf(`{"methodConfig":[{"name":[{"service":%q}],"retryPolicy":{`+
+		`"maxAttempts":%d,"initialBackoff":"%.3fs","maxBackoff":"%.3fs","backoffMultiplier":2,`+
+		`"retryableStatusCodes":["UNAVAILABLE"]}}]}`,
+		gen.RecommendService_ServiceDesc.ServiceName, r.MaxAttempts, r.InitialBackoff.Seconds(), r.MaxBackoff.Seconds())
+}
+
+// Recommend 返回用户 [from, to) 区间的推荐，推荐服务不可用时返回热门菜品
+func (c *Client) Recommend(ctx context.Context, userID uint, from, to int) (Result, error) {
+	if c.breaker.Allow() {
+		resp, err := c.call(ctx, userID, from, to)
+		switch {
+		case err == nil:
+			c.breaker.Success()
+			source := resp.Model
+			if source == "" {
+				source = SourceRecommender
+			}
+			responses.WithLabelValues(source).Inc()
+			items := resp.Recommendations
+			if items == nil {
+				items = []*gen.ShowMerchant{}
+			}
+			return Result{Items: items, Source: source, Experiment: resp.Experiment, Variant: resp.Variant}, nil
+		case ctx.Err() != nil:
+			// 调用方已取消，不计入熔断，也不必再查兜底
+			c.breaker.Release()
+			return Result{}, ctx.Err()
+		case isServiceFailure(err):
+			c.breaker.Failure()
+		default:
+			c.breaker.Release()
+		}
+		slog.WarnContext(ctx, "recommend call failed, serving popular dishes", "err", err)
+	}
+
+	dishes, err := c.fallback.Page(ctx, from, to)
+	if err != nil {
+		return Result{}, err
+	}
+	responses.WithLabelValues(SourcePopular).Inc()
+	return Result{Items: showMerchants(dishes), Source: SourcePopular, Fallback: true}, nil
+}
+
+func (c *Client) call(ctx context.Context, userID uint, from, to int) (*gen.DishRecommendResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := c.client.DishRecommend(ctx, &gen.DishRecommendRequest{
+		UserID: uint64(userID),
+		From:   uint32(from),
+		To:     uint32(to),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// isServiceFailure 推荐服务本身的故障才计入熔断，请求参数错误等不计入
+func isServiceFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// Ping 通过标准 gRPC 健康检查确认推荐服务可用
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{Service: gen.RecommendService_ServiceDesc.ServiceName})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return errors.New("recommend service is " + resp.Status.String())
+	}
+	return nil
+}
+
+// Close 关闭连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// showMerchants 把热门菜品转换为与推荐服务一致的展示结构，菜品需预加载 Store
+func showMerchants(dishes []model.Dishes) []*gen.ShowMerchant {
+	items := make([]*gen.ShowMerchant, 0, len(dishes))
+	for _, dish := range dishes {
+		items = append(items, &gen.ShowMerchant{
+			Img:        dish.ImageURL,
+			DishesName: dish.Name,
+			DishesID:   uint32(dish.ID),
+			StoreName:  dish.Store.Name,
+			Likenum:    uint32(dish.LikeNum),
+			Rating:     strconv.FormatFloat(dish.AvgRating, 'f', 1, 64),
+			Link:       "store/" + strconv.FormatUint(uint64(dish.StoreID), 10),
+			Reasons:    []*gen.Reason{{Source: SourcePopular, Text: "大家都在点"}},
+		})
+	}
+	return items
+}
@@ -2,28 +2,40 @@ package router
 
 import (
 	"Food_recommendation/Basic/controller"
+	"Food_recommendation/Basic/model"
 	"Food_recommendation/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"time"
 )
 
-func InitRouter() *gin.Engine {
-	router := gin.Default()
+func InitRouter(limiter *utils.RateLimiter) *gin.Engine {
+	router := gin.New()
+	router.Use(utils.RequestID(), otelgin.Middleware(utils.ServiceAPI), utils.HTTPMetrics(), utils.AccessLog(), gin.Recovery())
 
 	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"*"}                                                 // 允许前端域名
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}           // 允许的HTTP方法
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"} // 允许的请求头
-	config.AllowCredentials = true                                                      // 允许携带凭证（如cookie）
+	config.AllowOrigins = []string{"*"}                                                                                       // 允许前端域名
+	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}                                                 // 允许的HTTP方法
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Device-ID", utils.RequestIDHeader} // 允许的请求头
+	config.ExposeHeaders = []string{utils.RequestIDHeader}                                                                    // 前端可读取请求 ID 用于排查问题
+	config.AllowCredentials = true                                                                                            // 允许携带凭证（如cookie）
 	config.MaxAge = 12 * time.Hour
 	router.Use(cors.New(config))
+	router.Use(utils.ErrorHandler())
+	// 存活与就绪探针，Prometheus 指标在 server.metrics_addr 单独监听，不经过公网入口
+	router.GET("/healthz", controller.Healthz)
+	router.GET("/readyz", controller.Readyz)
 	merchant := router.Group("/api/merchant")
-	merchant.POST("/register", controller.MerchantRegister)
-	merchant.POST("/login", controller.MerchantLogin)
+	merchant.POST("/register", limiter.Limit("register"), controller.MerchantRegister)
+	merchant.POST("/login", limiter.Limit("login"), controller.MerchantLogin)
+	merchant.POST("/token/refresh", limiter.Limit("login"), controller.RefreshToken(utils.RoleMerchant))
+	merchant.POST("/verify-code", limiter.Limit("verify"), controller.SendCode(utils.RoleMerchant))
+	merchant.POST("/password/reset", limiter.Limit("verify"), controller.ResetPassword(utils.RoleMerchant))
 	authMerchant := merchant.Group("/")
-	authMerchant.Use(utils.AuthMiddleware())
+	authMerchant.Use(utils.MerchantAuth())
 	{
+		authMerchant.POST("/logout", controller.Logout)
 		//商家信息管理
 		authMerchant.GET("/profile", controller.GetMerchant)
 		authMerchant.PUT("/profile", controller.UpdateMerchant)
@@ -55,16 +67,39 @@ func InitRouter() *gin.Engine {
 	}
 
 	user := router.Group("/api/user")
-	user.POST("/register", controller.UserRegister)
-	user.POST("/login", controller.UserLogin)
-	user.GET("/search", controller.SearchHandler)
-	user.GET("/recommend", utils.AuthMiddleware(), controller.HandleItemCFRecommend)
-	user.GET("/stores/:storeId", utils.AuthMiddleware(), controller.AStore)
-	user.GET("/stores/:storeId/dishes/:dishId", utils.AuthMiddleware(), controller.DishHandler)
-	user.POST("/like", utils.AuthMiddleware(), controller.LikeDishHandler)
-	user.GET("/history", utils.AuthMiddleware(), controller.GetHistory)
-	user.GET("/search/key", utils.AuthMiddleware(), controller.GetSearchKey)
-	user.GET("/like", utils.AuthMiddleware(), controller.UserLike)
-	user.POST("/rating", utils.AuthMiddleware(), controller.RateDishHandler)
+	user.POST("/register", limiter.Limit("register"), controller.UserRegister)
+	user.POST("/login", limiter.Limit("login"), controller.UserLogin)
+	user.POST("/token/refresh", limiter.Limit("login"), controller.RefreshToken(utils.RoleUser))
+	user.POST("/verify-code", limiter.Limit("verify"), controller.SendCode(utils.RoleUser))
+	user.POST("/password/reset", limiter.Limit("verify"), controller.ResetPassword(utils.RoleUser))
+	user.POST("/logout", utils.UserAuth(), controller.Logout)
+	user.GET("/search", limiter.Limit("search"), controller.SearchHandler)
+	user.GET("/recommend", utils.UserAuth(), controller.HandleItemCFRecommend)
+	user.GET("/stores/:storeId", utils.UserAuth(), controller.AStore)
+	user.GET("/stores/:storeId/dishes/:dishId", utils.UserAuth(), controller.DishHandler)
+	user.POST("/like", utils.UserAuth(), controller.LikeDishHandler)
+	user.GET("/history", utils.UserAuth(), controller.GetHistory)
+	user.GET("/search/key", utils.UserAuth(), limiter.Limit("search"), controller.GetSearchKey)
+	user.GET("/like", utils.UserAuth(), controller.UserLike)
+	user.POST("/rating", utils.UserAuth(), controller.RateDishHandler)
+	user.POST("/events", utils.UserAuth(), limiter.Limit("events"), controller.PostEvents)
+	//不感兴趣的菜品、店铺和标签
+	notInterested := user.Group("/not-interested")
+	notInterested.Use(utils.UserAuth())
+	{
+		notInterested.GET("", controller.ListNotInterested)
+		notInterested.POST("/dishes/:dishId", controller.MarkNotInterested(model.NotInterestedDish))
+		notInterested.DELETE("/dishes/:dishId", controller.UnmarkNotInterested(model.NotInterestedDish))
+		notInterested.POST("/stores/:storeId", controller.MarkNotInterested(model.NotInterestedStore))
+		notInterested.DELETE("/stores/:storeId", controller.UnmarkNotInterested(model.NotInterestedStore))
+		notInterested.POST("/tags/:tag", controller.MarkNotInterested(model.NotInterestedTag))
+		notInterested.DELETE("/tags/:tag", controller.UnmarkNotInterested(model.NotInterestedTag))
+	}
+
+	admin := router.Group("/api/admin")
+	admin.Use(utils.AdminAuth())
+	{
+		admin.GET("/experiments/report", controller.ExperimentReport)
+	}
 	return router
 }
@@ -0,0 +1,94 @@
+package verify
+
+import (
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// 验证码用途，实际存储时会加上主体类型前缀，例如 user:register
+const (
+	PurposeRegister      = "register"
+	PurposeResetPassword = "reset_password"
+)
+
+// Store 验证码存储，dao.VerificationStore 为数据库实现
+type Store interface {
+	Create(ctx context.Context, target, purpose, codeHash string, ttl, interval time.Duration) error
+	Delete(ctx context.Context, target, purpose string) error
+	// Verify 校验验证码但不使其失效，返回匹配的验证码 ID
+	Verify(ctx context.Context, target, purpose, codeHash string, maxAttempts int) (uint, error)
+	// Consume 在同一事务中作废验证码并执行 fn：验证码已被使用时返回错误且不执行 fn，fn 失败时回滚作废
+	Consume(ctx context.Context, id uint, fn func(ctx context.Context) error) error
+}
+
+// Service 负责验证码的生成、发送和校验
+type Service struct {
+	store    Store
+	notifier utils.Notifier
+	cfg      config.Verify
+}
+
+func NewService(store Store, notifier utils.Notifier, cfg config.Verify) *Service {
+	return &Service{store: store, notifier: notifier, cfg: cfg}
+}
+
+// RequireOnRegister 注册是否必须携带验证码
+func (s *Service) RequireOnRegister() bool {
+	return s.cfg.RequireOnRegister
+}
+
+// Send 生成验证码并发送到 target，发送失败时删除记录以便立即重试
+func (s *Service) Send(ctx context.Context, target, purpose string) error {
+	code, err := generateCode(s.cfg.CodeLength)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Create(ctx, target, purpose, hashCode(target, purpose, code), s.cfg.TTL, s.cfg.ResendInterval); err != nil {
+		return err
+	}
+	msg := utils.Message{
+		To:      target,
+		Subject: "验证码",
+		Body:    fmt.Sprintf("您的验证码是 %s，%d 分钟内有效，请勿泄露给他人。", code, int(s.cfg.TTL.Minutes())),
+	}
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		_ = s.store.Delete(ctx, target, purpose)
+		return fmt.Errorf("send verification code failed: %w", err)
+	}
+	return nil
+}
+
+// Check 校验验证码，通过后作废验证码并执行 fn，两者在同一事务中：并发使用同一验证码时只有一个请求执行 fn，
+// fn 失败（如手机号已注册、数据库错误）时回滚，验证码仍可重试。fn 应使用收到的 ctx 访问数据库
+func (s *Service) Check(ctx context.Context, target, purpose, code string, fn func(ctx context.Context) error) error {
+	id, err := s.store.Verify(ctx, target, purpose, hashCode(target, purpose, code), s.cfg.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	return s.store.Consume(ctx, id, fn)
+}
+
+func generateCode(length int) (string, error) {
+	max := big.NewInt(10)
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
+
+func hashCode(target, purpose, code string) string {
+	sum := sha256.Sum256([]byte(purpose + "|" + target + "|" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,216 @@
+package verify
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"context"
+	"errors"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	cfg := config.Default().Database
+	cfg.Driver = "sqlite"
+	cfg.DSN = ":memory:"
+	dao.InitDB(cfg)
+	if err := dao.EnsureSchema(context.Background(), true); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeNotifier 记录每个目标最近收到的验证码
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent map[string]string
+	fail bool
+}
+
+var codePattern = regexp.MustCompile(`\d{6}`)
+
+func (f *fakeNotifier) Send(_ context.Context, msg utils.Message) error {
+	if f.fail {
+		return errors.New("notifier down")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent[msg.To] = codePattern.FindString(msg.Body)
+	return nil
+}
+
+func (f *fakeNotifier) code(target string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sent[target]
+}
+
+func newTestService(t *testing.T, f func(*config.Verify)) (*Service, *fakeNotifier) {
+	t.Helper()
+	cfg := config.Verify{CodeLength: 6, TTL: time.Minute, MaxAttempts: 3}
+	if f != nil {
+		f(&cfg)
+	}
+	n := &fakeNotifier{sent: make(map[string]string)}
+	return NewService(dao.VerificationStore{}, n, cfg), n
+}
+
+func noop(context.Context) error { return nil }
+
+func errCode(err error) string {
+	var e *model.Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+func TestSendAndCheck(t *testing.T) {
+	ctx := context.Background()
+	s, n := newTestService(t, nil)
+	if err := s.Send(ctx, "13600000001", PurposeRegister); err != nil {
+		t.Fatal(err)
+	}
+	code := n.code("13600000001")
+	if len(code) != 6 {
+		t.Fatalf("code = %q", code)
+	}
+	if err := s.Check(ctx, "13600000001", PurposeResetPassword, code, noop); errCode(err) != "code_invalid" {
+		t.Errorf("other purpose: err = %v", err)
+	}
+	if err := s.Check(ctx, "13600000001", PurposeRegister, code, noop); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := s.Check(ctx, "13600000001", PurposeRegister, code, noop); errCode(err) != "code_invalid" {
+		t.Errorf("second use: err = %v", err)
+	}
+}
+
+func TestResendInterval(t *testing.T) {
+	ctx := context.Background()
+	s, n := newTestService(t, func(c *config.Verify) { c.ResendInterval = time.Minute })
+	if err := s.Send(ctx, "13600000002", PurposeRegister); err != nil {
+		t.Fatal(err)
+	}
+	first := n.code("13600000002")
+	err := s.Send(ctx, "13600000002", PurposeRegister)
+	var e *model.Error
+	if !errors.As(err, &e) || e.Code != "code_too_frequent" || e.RetryAfter <= 0 {
+		t.Fatalf("resend: err = %v", err)
+	}
+	if n.code("13600000002") != first {
+		t.Error("resend within interval delivered a new code")
+	}
+
+	// 发送失败时删除记录，不占用重发间隔
+	n.fail = true
+	if err := s.Send(ctx, "13600000003", PurposeRegister); err == nil {
+		t.Fatal("send with failing notifier succeeded")
+	}
+	n.fail = false
+	if err := s.Send(ctx, "13600000003", PurposeRegister); err != nil {
+		t.Errorf("retry after failed send: %v", err)
+	}
+}
+
+func TestNewCodeReplacesOld(t *testing.T) {
+	ctx := context.Background()
+	s, n := newTestService(t, nil)
+	if err := s.Send(ctx, "13600000004", PurposeRegister); err != nil {
+		t.Fatal(err)
+	}
+	old := n.code("13600000004")
+	if err := s.Send(ctx, "13600000004", PurposeRegister); err != nil {
+		t.Fatal(err)
+	}
+	if fresh := n.code("13600000004"); fresh != old {
+		if err := s.Check(ctx, "13600000004", PurposeRegister, old, noop); errCode(err) != "code_invalid" {
+			t.Errorf("old code: err = %v", err)
+		}
+	}
+	if err := s.Check(ctx, "13600000004", PurposeRegister, n.code("13600000004"), noop); err != nil {
+		t.Errorf("new code: %v", err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	s, n := newTestService(t, func(c *config.Verify) { c.TTL = 50 * time.Millisecond })
+	if err := s.Send(ctx, "13600000005", PurposeRegister); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := s.Check(ctx, "13600000005", PurposeRegister, n.code("13600000005"), noop); errCode(err) != "code_invalid" {
+		t.Errorf("expired code: err = %v", err)
+	}
+}
+
+func TestMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	s, n := newTestService(t, nil)
+	if err := s.Send(ctx, "13600000006", PurposeRegister); err != nil {
+		t.Fatal(err)
+	}
+	code := n.code("13600000006")
+	wrong := "000000"
+	if wrong == code {
+		wrong = "111111"
+	}
+	for i := 0; i < 3; i++ {
+		if err := s.Check(ctx, "13600000006", PurposeRegister, wrong, noop); errCode(err) != "code_invalid" {
+			t.Fatalf("attempt %d: err = %v", i+1, err)
+		}
+	}
+	if err := s.Check(ctx, "13600000006", PurposeRegister, code, noop); errCode(err) != "code_invalid" {
+		t.Errorf("correct code after max attempts: err = %v", err)
+	}
+}
+
+func TestCheckRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	s, n := newTestService(t, nil)
+	if err := s.Send(ctx, "13600000007", PurposeResetPassword); err != nil {
+		t.Fatal(err)
+	}
+	code := n.code("13600000007")
+	failed := errors.New("account write failed")
+	if err := s.Check(ctx, "13600000007", PurposeResetPassword, code, func(context.Context) error { return failed }); !errors.Is(err, failed) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := s.Check(ctx, "13600000007", PurposeResetPassword, code, noop); err != nil {
+		t.Errorf("code after failed fn: %v", err)
+	}
+}
+
+func TestCheckConcurrentSingleUse(t *testing.T) {
+	ctx := context.Background()
+	s, n := newTestService(t, nil)
+	if err := s.Send(ctx, "13600000008", PurposeResetPassword); err != nil {
+		t.Fatal(err)
+	}
+	code := n.code("13600000008")
+	var mu sync.Mutex
+	runs := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.Check(ctx, "13600000008", PurposeResetPassword, code, func(context.Context) error {
+				mu.Lock()
+				runs++
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if runs != 1 {
+		t.Errorf("fn ran %d times, want 1", runs)
+	}
+}
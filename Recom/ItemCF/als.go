@@ -0,0 +1,184 @@
+package recommend
+
+import (
+	"Food_recommendation/config"
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ALS 隐式反馈矩阵分解推荐：点赞、评分和店铺浏览都视为正反馈，分值越高置信度越大。
+// 模型由 Update 训练后写入模型文件，各实例按文件修改时间检查并加载新版本。
+// 用户点赞、评分过或标记不感兴趣的菜品不再推荐
+type ALS struct {
+	data       Dataset
+	cfg        config.ALS
+	maxResults int
+	model      atomic.Pointer[alsModel]
+
+	mu      sync.Mutex
+	modTime time.Time // 最近一次加载的模型文件修改时间
+}
+
+// NewALS 创建 ALS 推荐器，需要先 Reload 或 Update 加载模型
+func NewALS(data Dataset, cfg config.ALS, maxResults int) *ALS {
+	return &ALS{data: data, cfg: cfg, maxResults: maxResults}
+}
+
+func (a *ALS) Recommend(ctx context.Context, userID uint) ([]ScoredDish, error) {
+	ctx, span := tracer.Start(ctx, "recommend.ALS")
+	defer span.End()
+	start := time.Now()
+	defer func() { computeDuration.WithLabelValues(ModelALS).Observe(time.Since(start).Seconds()) }()
+
+	m := a.model.Load()
+	if m == nil {
+		return nil, ErrNotReady
+	}
+	span.SetAttributes(attribute.Int64("model.version", m.Version))
+	profile, err := userProfile(ctx, a.data, userID)
+	if err != nil {
+		return nil, err
+	}
+	x := m.foldIn(profile)
+	if x == nil {
+		return []ScoredDish{}, nil
+	}
+	seen := consumed(profile)
+
+	type scored struct {
+		id    uint
+		row   int
+		score float64
+	}
+	all := make([]scored, 0, len(m.Items))
+	for i, id := range m.Items {
+		if !seen[id] {
+			all = append(all, scored{id: id, row: i, score: m.score(x, i)})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].id < all[j].id
+	})
+	// 多取一倍候选，过滤掉下架菜品后仍能凑满
+	if len(all) > 2*a.maxResults {
+		all = all[:2*a.maxResults]
+	}
+	// 推荐理由取隐向量与候选最接近的反馈菜品
+	var liked []int
+	for _, in := range profile {
+		if i, ok := m.index[in.DishID]; ok && strength(in, m.LikeWeight, m.VisitWeight) > 0 {
+			liked = append(liked, i)
+		}
+	}
+	ids := make([]uint, len(all))
+	scores := make(map[uint]float64, len(all))
+	refs := make(map[uint]uint, len(all))
+	for k, s := range all {
+		ids[k] = s.id
+		scores[s.id] = s.score
+		if i := m.closest(s.row, liked); i >= 0 {
+			refs[s.id] = m.Items[i]
+		}
+	}
+	dishes, names, err := dishesWithRefs(ctx, a.data, ids, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ScoredDish, 0, len(dishes))
+	for _, dish := range dishes {
+		res = append(res, ScoredDish{
+			Dish:    dish,
+			Score:   scores[dish.ID],
+			Reasons: []Reason{likedReason(ModelALS, names[refs[dish.ID]])},
+		})
+	}
+	candidateCount.Observe(float64(len(res)))
+	span.SetAttributes(attribute.Int("candidates", len(res)))
+
+	sortScored(res)
+	if len(res) > a.maxResults {
+		res = res[:a.maxResults]
+	}
+	return res, nil
+}
+
+// Update 用全部反馈训练模型，save 时写入模型文件并在本实例立即生效
+func (a *ALS) Update(ctx context.Context, save bool) (string, error) {
+	ctx, span := tracer.Start(ctx, "recommend.TrainALS")
+	defer span.End()
+	start := time.Now()
+
+	interactions, err := a.data.Interactions(ctx)
+	if err != nil {
+		return "", err
+	}
+	m, err := trainALS(ctx, interactions, a.cfg)
+	if err != nil {
+		return "", err
+	}
+	precomputeDuration.WithLabelValues(ModelALS).Observe(time.Since(start).Seconds())
+	if save {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if err := m.save(a.cfg.ModelPath); err != nil {
+			return "", err
+		}
+		if fi, err := os.Stat(a.cfg.ModelPath); err == nil {
+			a.modTime = fi.ModTime()
+		}
+		a.swap(m)
+	}
+	return fmt.Sprintf("%d interactions, %d dishes, %d factors, %d iterations",
+		len(interactions), len(m.Items), m.Factors, a.cfg.Iterations), nil
+}
+
+// Reload 模型文件修改时间变化且版本不同时加载并切换，文件不存在时不报错
+func (a *ALS) Reload(ctx context.Context) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fi, err := os.Stat(a.cfg.ModelPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if fi.ModTime().Equal(a.modTime) {
+		return false, nil
+	}
+	m, err := loadALS(a.cfg.ModelPath)
+	if err != nil {
+		return false, err
+	}
+	a.modTime = fi.ModTime()
+	if cur := a.model.Load(); cur != nil && cur.Version == m.Version {
+		return false, nil
+	}
+	a.swap(m)
+	return true, nil
+}
+
+// swap 原子替换模型
+func (a *ALS) swap(m *alsModel) {
+	a.model.Store(m)
+	modelVersion.WithLabelValues(ModelALS).Set(float64(m.Version))
+}
+
+func (a *ALS) Version() int64 {
+	if m := a.model.Load(); m != nil {
+		return m.Version
+	}
+	return 0
+}
@@ -0,0 +1,296 @@
+package recommend
+
+import (
+	"Food_recommendation/config"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// alsFormat 模型文件格式版本，字段含义变化时递增，旧格式的文件拒绝加载
+const alsFormat = 1
+
+// alsModel 训练好的 ALS 模型。只保存菜品向量，用户向量在请求时由用户当前的反馈现算（fold-in），
+// 训练之后新增的用户和反馈也能立即生效
+type alsModel struct {
+	Format         int
+	Version        int64 // 训练完成时间（Unix 毫秒）
+	Factors        int
+	Regularization float64
+	Alpha          float64
+	LikeWeight     float64
+	VisitWeight    float64
+	Items          []uint    // 行号 -> 菜品 ID
+	ItemFactors    []float64 // len(Items) × Factors，行优先
+
+	index map[uint]int // 菜品 ID -> 行号
+	gram  []float64    // YᵀY，fold-in 时复用
+}
+
+// alsEntry 稀疏矩阵中的一项，idx 为对侧（用户或菜品）的行号，r 为分值
+type alsEntry struct {
+	idx int
+	r   float64
+}
+
+// strength 用户对菜品的分值 r，置信度为 1 + Alpha × r
+func strength(in Interaction, likeWeight, visitWeight float64) float64 {
+	v := float64(in.Rating) + visitWeight*float64(in.Visits)
+	if in.Liked {
+		v += likeWeight
+	}
+	return v
+}
+
+// trainALS 隐式反馈 ALS（Hu, Koren & Volinsky 2008）：交替固定菜品向量求解用户向量、
+// 固定用户向量求解菜品向量，每一步对每行求解 (YᵀY + Yᵀ(Cu−I)Y + λI)x = YᵀCu·p(u)
+func trainALS(ctx context.Context, interactions []Interaction, cfg config.ALS) (*alsModel, error) {
+	users := make(map[uint]int)
+	m := &alsModel{
+		Format:         alsFormat,
+		Factors:        cfg.Factors,
+		Regularization: cfg.Regularization,
+		Alpha:          cfg.Alpha,
+		LikeWeight:     cfg.LikeWeight,
+		VisitWeight:    cfg.VisitWeight,
+		index:          make(map[uint]int),
+	}
+	var byUser, byItem [][]alsEntry
+	for _, in := range interactions {
+		r := strength(in, cfg.LikeWeight, cfg.VisitWeight)
+		if r <= 0 {
+			continue
+		}
+		u, ok := users[in.UserID]
+		if !ok {
+			u = len(byUser)
+			users[in.UserID] = u
+			byUser = append(byUser, nil)
+		}
+		i, ok := m.index[in.DishID]
+		if !ok {
+			i = len(m.Items)
+			m.index[in.DishID] = i
+			m.Items = append(m.Items, in.DishID)
+			byItem = append(byItem, nil)
+		}
+		byUser[u] = append(byUser[u], alsEntry{idx: i, r: r})
+		byItem[i] = append(byItem[i], alsEntry{idx: u, r: r})
+	}
+
+	// 固定种子，相同数据训练出相同的模型
+	rng := rand.New(rand.NewSource(1))
+	f := cfg.Factors
+	x := randomFactors(rng, len(byUser)*f, f)
+	y := randomFactors(rng, len(m.Items)*f, f)
+	for it := 0; it < cfg.Iterations; it++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		solveFactors(x, y, byUser, f, cfg.Regularization, cfg.Alpha)
+		solveFactors(y, x, byItem, f, cfg.Regularization, cfg.Alpha)
+	}
+
+	m.ItemFactors = y
+	m.Version = time.Now().UnixMilli()
+	m.gram = gramian(y, f)
+	return m, nil
+}
+
+func randomFactors(rng *rand.Rand, n, f int) []float64 {
+	v := make([]float64, n)
+	scale := 1 / math.Sqrt(float64(f))
+	for i := range v {
+		v[i] = rng.Float64() * scale
+	}
+	return v
+}
+
+// solveFactors 固定 src 求解 dst 的每一行，按 CPU 数并行
+func solveFactors(dst, src []float64, rows [][]alsEntry, f int, reg, alpha float64) {
+	gram := gramian(src, f)
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			a := make([]float64, f*f)
+			b := make([]float64, f)
+			for u := w; u < len(rows); u += workers {
+				solveRow(dst[u*f:(u+1)*f], src, gram, rows[u], f, reg, alpha, a, b)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// solveRow 求解一行向量写入 out，a、b 为调用方提供的缓冲区
+func solveRow(out, src, gram []float64, entries []alsEntry, f int, reg, alpha float64, a, b []float64) {
+	copy(a, gram)
+	for d := 0; d < f; d++ {
+		a[d*f+d] += reg
+		b[d] = 0
+	}
+	for _, e := range entries {
+		y := src[e.idx*f : (e.idx+1)*f]
+		c := 1 + alpha*e.r
+		for p := 0; p < f; p++ {
+			b[p] += c * y[p]
+			cy := (c - 1) * y[p]
+			for q := 0; q < f; q++ {
+				a[p*f+q] += cy * y[q]
+			}
+		}
+	}
+	choleskySolve(a, b, f)
+	copy(out, b)
+}
+
+// gramian 计算 VᵀV（f×f）
+func gramian(v []float64, f int) []float64 {
+	g := make([]float64, f*f)
+	for r := 0; r+f <= len(v); r += f {
+		row := v[r : r+f]
+		for p := 0; p < f; p++ {
+			for q := 0; q < f; q++ {
+				g[p*f+q] += row[p] * row[q]
+			}
+		}
+	}
+	return g
+}
+
+// choleskySolve 原地分解对称正定矩阵 a（n×n，行优先）并求解 a·x = b，结果写回 b
+func choleskySolve(a, b []float64, n int) {
+	for j := 0; j < n; j++ {
+		s := a[j*n+j]
+		for k := 0; k < j; k++ {
+			s -= a[j*n+k] * a[j*n+k]
+		}
+		// 正则项保证正定，这里只防御浮点误差
+		d := math.Sqrt(math.Max(s, 1e-12))
+		a[j*n+j] = d
+		for i := j + 1; i < n; i++ {
+			s := a[i*n+j]
+			for k := 0; k < j; k++ {
+				s -= a[i*n+k] * a[j*n+k]
+			}
+			a[i*n+j] = s / d
+		}
+	}
+	for i := 0; i < n; i++ {
+		s := b[i]
+		for k := 0; k < i; k++ {
+			s -= a[i*n+k] * b[k]
+		}
+		b[i] = s / a[i*n+i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		s := b[i]
+		for k := i + 1; k < n; k++ {
+			s -= a[k*n+i] * b[k]
+		}
+		b[i] = s / a[i*n+i]
+	}
+}
+
+// foldIn 用用户当前的反馈求解用户向量，没有模型中已知菜品的反馈时返回 nil
+func (m *alsModel) foldIn(profile []Interaction) []float64 {
+	entries := make([]alsEntry, 0, len(profile))
+	for _, in := range profile {
+		i, ok := m.index[in.DishID]
+		if r := strength(in, m.LikeWeight, m.VisitWeight); ok && r > 0 {
+			entries = append(entries, alsEntry{idx: i, r: r})
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	f := m.Factors
+	x := make([]float64, f)
+	solveRow(x, m.ItemFactors, m.gram, entries, f, m.Regularization, m.Alpha, make([]float64, f*f), make([]float64, f))
+	return x
+}
+
+// score 用户向量与第 i 个菜品向量的内积
+func (m *alsModel) score(x []float64, i int) float64 {
+	y := m.ItemFactors[i*m.Factors : (i+1)*m.Factors]
+	var s float64
+	for p, v := range x {
+		s += v * y[p]
+	}
+	return s
+}
+
+// closest 返回 rows 中与第 j 个菜品向量内积最大的行号，rows 为空时返回 -1
+func (m *alsModel) closest(j int, rows []int) int {
+	best, bestScore := -1, math.Inf(-1)
+	for _, i := range rows {
+		if s := m.score(m.ItemFactors[i*m.Factors:(i+1)*m.Factors], j); s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return best
+}
+
+// save 先写临时文件再改名，读取方不会读到写了一半的模型
+func (m *alsModel) save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create model dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".als-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create model file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	// CreateTemp 创建的文件只有属主可读，改为与普通文件一致
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod model file: %w", err)
+	}
+	if err := gob.NewEncoder(tmp).Encode(m); err != nil {
+		tmp.Close()
+		return fmt.Errorf("encode model: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write model file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace model file: %w", err)
+	}
+	return nil
+}
+
+// loadALS 读取模型文件并校验格式版本
+func loadALS(path string) (*alsModel, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var m alsModel
+	if err := gob.NewDecoder(file).Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode model %s: %w", path, err)
+	}
+	if m.Format != alsFormat {
+		return nil, fmt.Errorf("model %s has format %d, want %d", path, m.Format, alsFormat)
+	}
+	if m.Factors <= 0 || len(m.ItemFactors) != len(m.Items)*m.Factors {
+		return nil, fmt.Errorf("model %s is corrupted", path)
+	}
+	m.index = make(map[uint]int, len(m.Items))
+	for i, id := range m.Items {
+		m.index[id] = i
+	}
+	m.gram = gramian(m.ItemFactors, m.Factors)
+	return &m, nil
+}
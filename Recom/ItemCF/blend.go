@@ -0,0 +1,166 @@
+package recommend
+
+import (
+	"Food_recommendation/config"
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxReasons 每个推荐菜品最多附带的理由数
+const maxReasons = 3
+
+// Blend 混合推荐：并行调用多个模型，各自的得分归一化后按权重相加，推荐理由按各模型的贡献排序。
+// 未加载或出错的模型跳过，全部失败时才返回错误
+type Blend struct {
+	normalize  string
+	sources    []blendSource
+	models     modelSet
+	maxResults int
+}
+
+type blendSource struct {
+	name   string
+	weight float64
+	model  Recommender
+}
+
+// NewBlend 按 recommend.blend 创建混合推荐，models 为模型名称到推荐模型的映射
+func NewBlend(cfg config.Blend, models map[string]Recommender, maxResults int) (*Blend, error) {
+	b := &Blend{normalize: cfg.Normalize, maxResults: maxResults}
+	for _, src := range cfg.Sources {
+		m, ok := models[src.Model]
+		if !ok {
+			return nil, fmt.Errorf("unknown blend source %q", src.Model)
+		}
+		b.sources = append(b.sources, blendSource{name: src.Model, weight: src.Weight, model: m})
+		b.models = append(b.models, namedModel{name: src.Model, model: m})
+	}
+	return b, nil
+}
+
+func (b *Blend) Recommend(ctx context.Context, userID uint) ([]ScoredDish, error) {
+	ctx, span := tracer.Start(ctx, "recommend.Blend")
+	defer span.End()
+	start := time.Now()
+	defer func() { computeDuration.WithLabelValues(ModelBlend).Observe(time.Since(start).Seconds()) }()
+
+	// 各来源共用一次用户画像查询
+	ctx = withProfile(ctx, userID)
+	results := make([][]ScoredDish, len(b.sources))
+	errs := make([]error, len(b.sources))
+	var wg sync.WaitGroup
+	for i, src := range b.sources {
+		wg.Add(1)
+		go func(i int, src blendSource) {
+			defer wg.Done()
+			results[i], errs[i] = src.model.Recommend(ctx, userID)
+		}(i, src)
+	}
+	wg.Wait()
+
+	// contribution 记录每个来源对菜品的加权得分，用于给推荐理由排序
+	type contrib struct {
+		score   float64
+		reasons []Reason
+	}
+	merged := make(map[uint]*ScoredDish)
+	contribs := make(map[uint][]contrib)
+	var used []string
+	var lastErr error
+	for i, src := range b.sources {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], ErrNotReady) {
+				slog.ErrorContext(ctx, "blend source failed", "model", src.name, "err", errs[i])
+				lastErr = errs[i]
+			}
+			continue
+		}
+		used = append(used, src.name)
+		norm := normalizeScores(results[i], b.normalize)
+		for k, sd := range results[i] {
+			v := src.weight * norm[k]
+			if merged[sd.Dish.ID] == nil {
+				merged[sd.Dish.ID] = &ScoredDish{Dish: sd.Dish}
+			}
+			merged[sd.Dish.ID].Score += v
+			contribs[sd.Dish.ID] = append(contribs[sd.Dish.ID], contrib{score: v, reasons: sd.Reasons})
+		}
+	}
+	span.SetAttributes(attribute.String("sources", strings.Join(used, ",")))
+	if len(used) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, ErrNotReady
+	}
+
+	res := make([]ScoredDish, 0, len(merged))
+	for id, sd := range merged {
+		cs := contribs[id]
+		sort.SliceStable(cs, func(i, j int) bool { return cs[i].score > cs[j].score })
+		seen := make(map[string]bool)
+		for _, c := range cs {
+			for _, r := range c.reasons {
+				if len(sd.Reasons) < maxReasons && !seen[r.Text] {
+					seen[r.Text] = true
+					sd.Reasons = append(sd.Reasons, r)
+				}
+			}
+		}
+		res = append(res, *sd)
+	}
+	candidateCount.Observe(float64(len(res)))
+	span.SetAttributes(attribute.Int("candidates", len(res)))
+
+	sortScored(res)
+	if len(res) > b.maxResults {
+		res = res[:b.maxResults]
+	}
+	return res, nil
+}
+
+// normalizeScores 把按得分降序排列的结果归一化到 [0, 1]，返回与 res 下标对应的分数
+func normalizeScores(res []ScoredDish, method string) []float64 {
+	norm := make([]float64, len(res))
+	if len(res) == 0 {
+		return norm
+	}
+	hi, lo := res[0].Score, res[len(res)-1].Score
+	for i, sd := range res {
+		switch method {
+		case "rank":
+			norm[i] = 1 - float64(i)/float64(len(res))
+		case "minmax":
+			if hi > lo {
+				norm[i] = (sd.Score - lo) / (hi - lo)
+			} else {
+				norm[i] = 1
+			}
+		default:
+			if hi > 0 && sd.Score > 0 {
+				norm[i] = sd.Score / hi
+			}
+		}
+	}
+	return norm
+}
+
+func (b *Blend) Update(ctx context.Context, save bool) (string, error) {
+	return b.models.Update(ctx, save)
+}
+
+func (b *Blend) Reload(ctx context.Context) (bool, error) {
+	return b.models.Reload(ctx)
+}
+
+// Version 返回各来源中最旧的版本，有来源未加载时返回 0，启动时会触发一次全量计算
+func (b *Blend) Version() int64 {
+	return b.models.Version()
+}
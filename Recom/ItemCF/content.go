@@ -0,0 +1,374 @@
+package recommend
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Content 基于内容的推荐：不依赖其他用户的反馈，新上架的菜品只要有标签、价格和店铺就能被推荐。
+// 特征索引在内存中由菜品目录重建，目录没有变化时 Reload 不会切换版本。用户点赞、评分过或标记不感兴趣的菜品不再推荐
+type Content struct {
+	name       string // 模型名称，用于指标标签和推荐理由
+	data       Dataset
+	cfg        config.Content
+	maxResults int
+	index      atomic.Pointer[contentIndex]
+}
+
+// contentIndex 菜品特征的倒排索引，每个菜品的特征向量已归一化
+type contentIndex struct {
+	version     int64
+	fingerprint uint64
+	features    map[uint]map[string]float64 // 菜品 -> 特征 -> 权重
+	postings    map[string][]posting        // 特征 -> 含该特征的菜品
+	texts       map[uint]string             // 菜品 -> 名称、描述和标签（小写），用于匹配搜索关键词
+	grams       map[string][]uint           // 文本中的单字和相邻两字 -> 含该片段的菜品，用于查找关键词的候选
+	dishes      []uint
+	tags        int
+}
+
+type posting struct {
+	dishID uint
+	weight float64
+}
+
+// NewContent 创建基于内容的推荐器，需要先 Reload 或 Update 构建索引
+func NewContent(data Dataset, cfg config.Content, maxResults int) *Content {
+	return &Content{name: ModelContent, data: data, cfg: cfg, maxResults: maxResults}
+}
+
+// NewSearch 创建只按最近搜索关键词匹配菜品的推荐器，标签、价格和店铺特征不参与打分
+func NewSearch(data Dataset, maxResults int) *Content {
+	return &Content{name: ModelSearch, data: data, cfg: config.Content{KeywordWeight: 1}, maxResults: maxResults}
+}
+
+// contribution 某一特征或关键词对菜品得分的贡献，用于生成推荐理由
+type contribution struct {
+	key   string // 特征名，关键词匹配时为 "keyword:" 加关键词
+	score float64
+}
+
+func (c *Content) Recommend(ctx context.Context, userID uint) ([]ScoredDish, error) {
+	ctx, span := tracer.Start(ctx, "recommend.Content")
+	defer span.End()
+	span.SetAttributes(attribute.String("model", c.name))
+	start := time.Now()
+	defer func() { computeDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds()) }()
+
+	idx := c.index.Load()
+	if idx == nil {
+		return nil, ErrNotReady
+	}
+	span.SetAttributes(attribute.Int64("model.version", idx.version))
+	profile, err := userProfile(ctx, c.data, userID)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := c.data.Keywords(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户画像：反馈过的菜品特征按分值加权求和后归一化
+	user := make(map[string]float64)
+	seen := consumed(profile)
+	for _, in := range profile {
+		r := strength(in, c.cfg.LikeWeight, c.cfg.VisitWeight)
+		for f, w := range idx.features[in.DishID] {
+			user[f] += r * w
+		}
+	}
+	normalize(user)
+
+	// best 记录每个菜品贡献最大的特征和关键词
+	scores := make(map[uint]float64)
+	best := make(map[uint][]contribution)
+	for f, uw := range user {
+		for _, p := range idx.postings[f] {
+			v := uw * p.weight
+			scores[p.dishID] += v
+			best[p.dishID] = keepBest(best[p.dishID], contribution{key: f, score: v}, false)
+		}
+	}
+	if kws := normalizeKeywords(keywords); len(kws) > 0 && c.cfg.KeywordWeight > 0 {
+		hits := make(map[uint]int)
+		first := make(map[uint]string)
+		for _, kw := range kws {
+			for _, id := range idx.match(kw) {
+				if hits[id] == 0 {
+					first[id] = kw
+				}
+				hits[id]++
+			}
+		}
+		for id, n := range hits {
+			v := c.cfg.KeywordWeight * float64(n) / float64(len(kws))
+			scores[id] += v
+			best[id] = keepBest(best[id], contribution{key: "keyword:" + first[id], score: v}, true)
+		}
+	}
+
+	type scored struct {
+		id    uint
+		score float64
+	}
+	all := make([]scored, 0, len(scores))
+	for id, s := range scores {
+		if !seen[id] && s > 0 {
+			all = append(all, scored{id: id, score: s})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].id < all[j].id
+	})
+	// 索引可能落后于目录，多取一倍候选，过滤掉刚下架的菜品后仍能凑满
+	if len(all) > 2*c.maxResults {
+		all = all[:2*c.maxResults]
+	}
+	ids := make([]uint, len(all))
+	for i, s := range all {
+		ids[i] = s.id
+	}
+	dishes, err := c.data.Dishes(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ScoredDish, 0, len(dishes))
+	for _, dish := range dishes {
+		res = append(res, ScoredDish{Dish: dish, Score: scores[dish.ID], Reasons: c.reasons(dish, best[dish.ID])})
+	}
+	candidateCount.Observe(float64(len(res)))
+	span.SetAttributes(attribute.Int("candidates", len(res)))
+
+	sortScored(res)
+	if len(res) > c.maxResults {
+		res = res[:c.maxResults]
+	}
+	return res, nil
+}
+
+// Update 由菜品目录重建特征索引，save 时在本实例立即生效（索引只在内存中，无需持久化）
+func (c *Content) Update(ctx context.Context, save bool) (string, error) {
+	idx, err := c.build(ctx)
+	if err != nil {
+		return "", err
+	}
+	if save {
+		c.swap(idx)
+	}
+	return fmt.Sprintf("%d dishes, %d tags", len(idx.dishes), idx.tags), nil
+}
+
+// Reload 重建索引，菜品目录没有变化时不切换
+func (c *Content) Reload(ctx context.Context) (bool, error) {
+	idx, err := c.build(ctx)
+	if err != nil {
+		return false, err
+	}
+	if cur := c.index.Load(); cur != nil && cur.fingerprint == idx.fingerprint {
+		return false, nil
+	}
+	c.swap(idx)
+	return true, nil
+}
+
+func (c *Content) swap(idx *contentIndex) {
+	c.index.Store(idx)
+	modelVersion.WithLabelValues(c.name).Set(float64(idx.version))
+}
+
+func (c *Content) Version() int64 {
+	if idx := c.index.Load(); idx != nil {
+		return idx.version
+	}
+	return 0
+}
+
+// build 计算每个菜品的特征向量：标签按 TF-IDF（同一菜品的标签不重复，TF 为 1）加权，
+// 价格区间和店铺各为一个特征
+func (c *Content) build(ctx context.Context) (*contentIndex, error) {
+	ctx, span := tracer.Start(ctx, "recommend.BuildContent")
+	defer span.End()
+	start := time.Now()
+	defer func() { precomputeDuration.WithLabelValues(c.name).Observe(time.Since(start).Seconds()) }()
+
+	dishes, err := c.data.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	df := make(map[string]int)
+	for _, d := range dishes {
+		for _, t := range d.Tags {
+			df[t.Name]++
+		}
+	}
+
+	idx := &contentIndex{
+		version:  time.Now().UnixMilli(),
+		features: make(map[uint]map[string]float64, len(dishes)),
+		postings: make(map[string][]posting),
+		texts:    make(map[uint]string, len(dishes)),
+		grams:    make(map[string][]uint),
+		dishes:   make([]uint, 0, len(dishes)),
+		tags:     len(df),
+	}
+	h := fnv.New64a()
+	n := float64(len(dishes))
+	for _, d := range dishes {
+		band := priceBand(d, c.cfg.PriceBands)
+		vec := map[string]float64{
+			"price:" + strconv.Itoa(band):                        c.cfg.PriceWeight,
+			"store:" + strconv.FormatUint(uint64(d.StoreID), 10): c.cfg.StoreWeight,
+		}
+		text := []string{d.Name, d.Desc}
+		for _, t := range d.Tags {
+			idf := math.Log((1+n)/(1+float64(df[t.Name]))) + 1
+			vec["tag:"+t.Name] = c.cfg.TagWeight * idf
+			text = append(text, t.Name)
+		}
+		normalize(vec)
+		idx.features[d.ID] = vec
+		for f, w := range vec {
+			if w > 0 {
+				idx.postings[f] = append(idx.postings[f], posting{dishID: d.ID, weight: w})
+			}
+		}
+		idx.addText(d.ID, strings.ToLower(strings.Join(text, " ")))
+		idx.dishes = append(idx.dishes, d.ID)
+		fmt.Fprintf(h, "%d|%d|%d|%s\n", d.ID, d.StoreID, band, idx.texts[d.ID])
+	}
+	idx.fingerprint = h.Sum64()
+	return idx, nil
+}
+
+// addText 记录菜品文本，并把其中每个单字和相邻两字加入倒排索引
+func (idx *contentIndex) addText(id uint, text string) {
+	idx.texts[id] = text
+	r := []rune(text)
+	seen := make(map[string]bool, 2*len(r))
+	for i := range r {
+		for j := i + 1; j <= i+2 && j <= len(r); j++ {
+			if g := string(r[i:j]); !seen[g] {
+				seen[g] = true
+				idx.grams[g] = append(idx.grams[g], id)
+			}
+		}
+	}
+}
+
+// match 返回文本包含关键词 kw 的菜品：取 kw 中最少见的相邻两字（单字关键词取该字）对应的菜品为候选，
+// 超过两字时再逐个核对候选的文本，不扫描整个目录
+func (idx *contentIndex) match(kw string) []uint {
+	r := []rune(kw)
+	if len(r) == 1 {
+		return idx.grams[kw]
+	}
+	var cands []uint
+	for i := 0; i+1 < len(r); i++ {
+		p := idx.grams[string(r[i:i+2])]
+		if len(p) == 0 {
+			return nil
+		}
+		if i == 0 || len(p) < len(cands) {
+			cands = p
+		}
+	}
+	if len(r) == 2 {
+		return cands
+	}
+	var res []uint
+	for _, id := range cands {
+		if strings.Contains(idx.texts[id], kw) {
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// keepBest 维护最多两项贡献：下标 0 为特征中贡献最大的一项，下标 1 为关键词匹配
+func keepBest(cs []contribution, c contribution, keyword bool) []contribution {
+	if cs == nil {
+		cs = make([]contribution, 2)
+	}
+	i := 0
+	if keyword {
+		i = 1
+	}
+	if c.score > cs[i].score || (c.score == cs[i].score && c.key < cs[i].key) {
+		cs[i] = c
+	}
+	return cs
+}
+
+// reasons 把贡献按大小转为推荐理由
+func (c *Content) reasons(dish model.Dishes, cs []contribution) []Reason {
+	cs = append([]contribution(nil), cs...)
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].score > cs[j].score })
+	res := make([]Reason, 0, len(cs))
+	for _, ct := range cs {
+		if ct.score <= 0 {
+			continue
+		}
+		kind, value, _ := strings.Cut(ct.key, ":")
+		switch kind {
+		case "tag":
+			res = append(res, tagReason(c.name, value))
+		case "store":
+			res = append(res, storeReason(c.name, dish.Store.Name))
+		case "price":
+			res = append(res, priceReason(c.name))
+		case "keyword":
+			res = append(res, searchReason(c.name, value))
+		}
+	}
+	return res
+}
+
+// priceBand 返回价格所在区间的序号，bands 为升序的区间上界
+func priceBand(d model.Dishes, bands []float64) int {
+	price := d.Price.InexactFloat64()
+	return sort.Search(len(bands), func(i int) bool { return price < bands[i] })
+}
+
+// normalize 把稀疏向量缩放为单位长度，零向量保持不变
+func normalize(v map[string]float64) {
+	var sum float64
+	for _, w := range v {
+		sum += w * w
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for f, w := range v {
+		v[f] = w / norm
+	}
+}
+
+// normalizeKeywords 去掉空白和重复的关键词并转为小写
+func normalizeKeywords(keywords []string) []string {
+	seen := make(map[string]bool, len(keywords))
+	res := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		kw = strings.ToLower(strings.TrimSpace(kw))
+		if kw != "" && !seen[kw] {
+			seen[kw] = true
+			res = append(res, kw)
+		}
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package recommend
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContentIndexMatch(t *testing.T) {
+	texts := map[uint]string{
+		1: "麻辣香锅 香辣过瘾 川菜",
+		2: "香辣鸡翅 炸鸡",
+		3: "番茄炒蛋 家常菜",
+		4: "beef noodle soup 面食",
+	}
+	idx := &contentIndex{texts: make(map[uint]string), grams: make(map[string][]uint)}
+	for id := uint(1); id <= 4; id++ {
+		idx.addText(id, texts[id])
+	}
+	tests := []struct {
+		kw   string
+		want []uint
+	}{
+		{kw: "香辣", want: []uint{1, 2}},
+		{kw: "麻辣香锅", want: []uint{1}},
+		{kw: "辣香", want: []uint{1}},
+		{kw: "菜", want: []uint{1, 3}},
+		{kw: "noodle", want: []uint{4}},
+		{kw: "noodle soup", want: []uint{4}},
+		// 每个相邻两字都出现过，但整个关键词不在任何文本中
+		{kw: "辣香辣", want: nil},
+		{kw: "火锅", want: nil},
+		{kw: "锅", want: []uint{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kw, func(t *testing.T) {
+			got := idx.match(tt.kw)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("match(%q) = %v, want %v", tt.kw, got, tt.want)
+			}
+			// 与逐个扫描文本的结果一致
+			var scan []uint
+			for id := uint(1); id <= 4; id++ {
+				if strings.Contains(texts[id], tt.kw) {
+					scan = append(scan, id)
+				}
+			}
+			if !reflect.DeepEqual(got, scan) {
+				t.Errorf("match(%q) = %v, scan = %v", tt.kw, got, scan)
+			}
+		})
+	}
+}
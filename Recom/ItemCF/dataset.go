@@ -0,0 +1,201 @@
+package recommend
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"context"
+	"sort"
+	"sync"
+)
+
+// Interaction 用户对菜品的反馈，Liked 为是否点赞，Rating 为评分分数（0 表示未评分），
+// Visits 为用户浏览菜品所在店铺的次数，Clicks 为在推荐结果中点击的次数，
+// Dismissed 为是否在推荐结果中点过不感兴趣或把菜品标记为不感兴趣
+type Interaction struct {
+	UserID    uint
+	DishID    uint
+	Liked     bool
+	Rating    uint
+	Visits    uint
+	Clicks    uint
+	Dismissed bool
+}
+
+// consumed 用户点赞、评分过或标记不感兴趣的菜品，各模型都不再推荐
+func consumed(profile []Interaction) map[uint]bool {
+	res := make(map[uint]bool)
+	for _, in := range profile {
+		if in.Liked || in.Rating > 0 || in.Dismissed {
+			res[in.DishID] = true
+		}
+	}
+	return res
+}
+
+// profileKey 请求内共享的用户画像，见 withProfile
+type profileKey struct{}
+
+type profileCache struct {
+	userID  uint
+	once    sync.Once
+	profile []Interaction
+	err     error
+}
+
+// withProfile 让同一请求内的各模型共用一次 UserInteractions 查询，Blend 并行调用多个来源时使用。
+// 共享的画像只读，模型不能修改
+func withProfile(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, profileKey{}, &profileCache{userID: userID})
+}
+
+// userProfile 返回用户画像，ctx 上有 withProfile 的缓存时只查询一次
+func userProfile(ctx context.Context, data Dataset, userID uint) ([]Interaction, error) {
+	c, ok := ctx.Value(profileKey{}).(*profileCache)
+	if !ok || c.userID != userID {
+		return data.UserInteractions(ctx, userID)
+	}
+	c.once.Do(func() { c.profile, c.err = data.UserInteractions(ctx, userID) })
+	return c.profile, c.err
+}
+
+// Dataset 推荐算法的数据来源，DBDataset 为数据库实现
+type Dataset interface {
+	// Interactions 返回所有用户的点赞、评分、店铺浏览和推荐反馈，同一用户对同一菜品合并为一条
+	Interactions(ctx context.Context) ([]Interaction, error)
+	// UserInteractions 返回单个用户的点赞、评分、店铺浏览和推荐反馈
+	UserInteractions(ctx context.Context, userID uint) ([]Interaction, error)
+	// Dishes 返回指定 ID 中可推荐（在售且店铺营业）的菜品，需预加载 Store 和 Tags
+	Dishes(ctx context.Context, ids []uint) ([]model.Dishes, error)
+	// Catalog 返回所有可推荐的菜品，需预加载 Store 和 Tags
+	Catalog(ctx context.Context) ([]model.Dishes, error)
+	// Keywords 返回用户最近搜索的关键词
+	Keywords(ctx context.Context, userID uint) ([]string, error)
+	// Popular 返回按点赞数和评分排序的前 limit 个可推荐菜品，需预加载 Store 和 Tags
+	Popular(ctx context.Context, limit int) ([]model.Dishes, error)
+}
+
+// DBDataset 从数据库读取点赞、评分、浏览记录、推荐反馈和在售菜品
+type DBDataset struct{}
+
+func (d DBDataset) Interactions(ctx context.Context) ([]Interaction, error) {
+	likes, err := dao.AllLikes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := dao.AllRatings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visits, err := dao.AllStoreVisits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signals, err := dao.AllEventSignals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dismissed, err := dao.AllDismissedDishes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.merge(ctx, likes, ratings, visits, append(signals, dismissed...))
+}
+
+func (d DBDataset) UserInteractions(ctx context.Context, userID uint) ([]Interaction, error) {
+	likes, err := dao.UserLikes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := dao.UserRatings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	visits, err := dao.UserStoreVisits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	signals, err := dao.UserEventSignals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	dismissed, err := dao.UserDismissedDishes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return d.merge(ctx, likes, ratings, visits, append(signals, dismissed...))
+}
+
+func (DBDataset) Catalog(ctx context.Context) ([]model.Dishes, error) {
+	return dao.CatalogDishes(ctx)
+}
+
+func (DBDataset) Keywords(ctx context.Context, userID uint) ([]string, error) {
+	return dao.AllSearch(ctx, userID)
+}
+
+func (DBDataset) Popular(ctx context.Context, limit int) ([]model.Dishes, error) {
+	return dao.PopularDishesWithTags(ctx, limit)
+}
+
+// merge 把店铺浏览展开到店内在售菜品后与点赞、评分、推荐反馈合并
+func (DBDataset) merge(ctx context.Context, likes []model.Like, ratings []model.Rating, visits []dao.StoreVisit, signals []dao.EventSignal) ([]Interaction, error) {
+	storeIDs := make([]uint, 0, len(visits))
+	seen := make(map[uint]bool)
+	for _, v := range visits {
+		if !seen[v.StoreID] {
+			seen[v.StoreID] = true
+			storeIDs = append(storeIDs, v.StoreID)
+		}
+	}
+	dishes, err := dao.AvailableDishIDsByStore(ctx, storeIDs)
+	if err != nil {
+		return nil, err
+	}
+	return MergeInteractions(likes, ratings, visits, signals, dishes), nil
+}
+
+func (DBDataset) Dishes(ctx context.Context, ids []uint) ([]model.Dishes, error) {
+	return dao.AvailableDishesByID(ctx, ids)
+}
+
+// MergeInteractions 把同一用户对同一菜品的点赞、评分、浏览和推荐反馈合并为一条，按用户和菜品排序。
+// storeDishes 为店铺到店内菜品的映射，用于把店铺浏览计到每个菜品上
+func MergeInteractions(likes []model.Like, ratings []model.Rating, visits []dao.StoreVisit, signals []dao.EventSignal, storeDishes map[uint][]uint) []Interaction {
+	type key struct{ user, dish uint }
+	merged := make(map[key]*Interaction, len(likes)+len(ratings))
+	get := func(userID, dishID uint) *Interaction {
+		k := key{userID, dishID}
+		if merged[k] == nil {
+			merged[k] = &Interaction{UserID: userID, DishID: dishID}
+		}
+		return merged[k]
+	}
+	for _, l := range likes {
+		get(l.UserID, l.DishID).Liked = true
+	}
+	for _, r := range ratings {
+		get(r.UserID, r.DishID).Rating = r.Num
+	}
+	for _, v := range visits {
+		for _, dishID := range storeDishes[v.StoreID] {
+			get(v.UserID, dishID).Visits += v.Visits
+		}
+	}
+	for _, s := range signals {
+		in := get(s.UserID, s.DishID)
+		in.Clicks += s.Clicks
+		in.Dismissed = in.Dismissed || s.Dismisses > 0
+	}
+
+	res := make([]Interaction, 0, len(merged))
+	for _, in := range merged {
+		res = append(res, *in)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].UserID != res[j].UserID {
+			return res[i].UserID < res[j].UserID
+		}
+		return res[i].DishID < res[j].DishID
+	})
+	return res
+}
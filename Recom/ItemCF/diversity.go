@@ -0,0 +1,125 @@
+package recommend
+
+import "Food_recommendation/config"
+
+// SourceSerendipity 多样性重排放入长尾位置的菜品的推荐理由来源
+const SourceSerendipity = "serendipity"
+
+// Diversify 按 recommend.diversity 对按得分排序的推荐做多样性重排，返回新的切片，不修改 dishes 及其推荐理由
+// （dishes 可能与模型共享，如热门列表）。菜品数量不变，只调整顺序
+func Diversify(dishes []ScoredDish, cfg config.Diversity) []ScoredDish {
+	if !cfg.Enabled || len(dishes) < 2 {
+		return dishes
+	}
+	n := len(dishes)
+	var top float64
+	for _, d := range dishes {
+		top = max(top, d.Score)
+	}
+	rel := make([]float64, n)
+	tags := make([]map[string]bool, n)
+	for i, d := range dishes {
+		if top > 0 {
+			rel[i] = d.Score / top
+		}
+		tags[i] = make(map[string]bool, len(d.Dish.Tags))
+		for _, t := range d.Dish.Tags {
+			tags[i][t.Name] = true
+		}
+	}
+	sim := func(i, j int) float64 {
+		var s float64
+		if dishes[i].Dish.StoreID == dishes[j].Dish.StoreID {
+			s += cfg.StoreWeight
+		}
+		return s + cfg.TagWeight*jaccard(tags[i], tags[j])
+	}
+
+	// maxSim 每个候选与已选菜品的最大相似度，每选出一个菜品增量更新
+	maxSim := make([]float64, n)
+	used := make([]bool, n)
+	res := make([]ScoredDish, 0, n)
+	for p := 0; p < n; p++ {
+		// 当前窗口内已选菜品的店铺和标签计数
+		stores := make(map[uint]int)
+		tagCount := make(map[string]int)
+		for _, d := range res[p-p%cfg.Window:] {
+			stores[d.Dish.StoreID]++
+			for _, t := range d.Dish.Tags {
+				tagCount[t.Name]++
+			}
+		}
+		withinCaps := func(i int) bool {
+			if cfg.MaxPerStore > 0 && stores[dishes[i].Dish.StoreID] >= cfg.MaxPerStore {
+				return false
+			}
+			if cfg.MaxPerTag > 0 {
+				for t := range tags[i] {
+					if tagCount[t] >= cfg.MaxPerTag {
+						return false
+					}
+				}
+			}
+			return true
+		}
+		longTail := func(i int) bool {
+			return withinCaps(i) && int(dishes[i].Dish.LikeNum) <= cfg.LongTailLikes
+		}
+		pick := func(ok func(int) bool) int {
+			best := -1
+			var bestScore float64
+			for i := range dishes {
+				if used[i] || (ok != nil && !ok(i)) {
+					continue
+				}
+				s := cfg.Lambda*rel[i] - (1-cfg.Lambda)*maxSim[i]
+				if best < 0 || s > bestScore {
+					best, bestScore = i, s
+				}
+			}
+			return best
+		}
+
+		best := -1
+		serendipity := cfg.SerendipitySlot > 0 && (p+1)%cfg.SerendipitySlot == 0
+		if serendipity {
+			best = pick(longTail)
+		}
+		if best < 0 {
+			serendipity = false
+			best = pick(withinCaps)
+		}
+		if best < 0 {
+			// 剩余菜品都超出上限，放宽限制，不丢弃菜品
+			best = pick(nil)
+		}
+		used[best] = true
+		d := dishes[best]
+		if serendipity {
+			reasons := make([]Reason, 0, maxReasons)
+			reasons = append(reasons, serendipityReason())
+			d.Reasons = append(reasons, d.Reasons[:min(len(d.Reasons), maxReasons-1)]...)
+		}
+		res = append(res, d)
+		for i := range dishes {
+			if !used[i] {
+				maxSim[i] = max(maxSim[i], sim(best, i))
+			}
+		}
+	}
+	return res
+}
+
+// jaccard 两个标签集合的交集大小除以并集大小，都为空时为 0
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for t := range a {
+		if b[t] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
@@ -0,0 +1,135 @@
+package recommend
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"reflect"
+	"testing"
+)
+
+func scored(id, store, likes uint, score float64, tags ...string) ScoredDish {
+	d := ScoredDish{
+		Dish:    model.Dishes{ID: id, StoreID: store, LikeNum: likes},
+		Score:   score,
+		Reasons: []Reason{{Source: ModelItemCF, Text: "a"}, {Source: ModelContent, Text: "b"}, {Source: ModelPopular, Text: "c"}},
+	}
+	for _, t := range tags {
+		d.Dish.Tags = append(d.Dish.Tags, model.Tag{Name: t})
+	}
+	return d
+}
+
+func TestDiversify(t *testing.T) {
+	// Lambda 为 1 时只按得分选，便于单独验证上限和长尾位置
+	base := config.Diversity{Enabled: true, Lambda: 1, Window: 4}
+	with := func(f func(*config.Diversity)) config.Diversity {
+		cfg := base
+		f(&cfg)
+		return cfg
+	}
+	tests := []struct {
+		name        string
+		dishes      []ScoredDish
+		cfg         config.Diversity
+		want        []uint
+		serendipity uint // 放入长尾位置的菜品，0 表示没有
+	}{
+		{
+			name:   "disabled",
+			dishes: []ScoredDish{scored(1, 1, 100, 1), scored(2, 1, 100, 2)},
+			cfg:    with(func(c *config.Diversity) { c.Enabled = false }),
+			want:   []uint{1, 2},
+		},
+		{
+			name:   "single dish",
+			dishes: []ScoredDish{scored(1, 1, 100, 1)},
+			cfg:    base,
+			want:   []uint{1},
+		},
+		{
+			name:   "no caps keeps score order",
+			dishes: []ScoredDish{scored(1, 1, 100, 5), scored(2, 1, 100, 4), scored(3, 2, 100, 3), scored(4, 2, 100, 2)},
+			cfg:    base,
+			want:   []uint{1, 2, 3, 4},
+		},
+		{
+			// 第 4 位只剩店铺 1 的菜品，放宽上限；第 5 位进入新窗口
+			name: "per-store cap",
+			dishes: []ScoredDish{
+				scored(1, 1, 100, 5), scored(2, 1, 100, 4), scored(3, 2, 100, 3), scored(4, 3, 100, 2), scored(5, 1, 100, 1),
+			},
+			cfg:  with(func(c *config.Diversity) { c.MaxPerStore = 1 }),
+			want: []uint{1, 3, 4, 2, 5},
+		},
+		{
+			name:   "per-store cap resets each window",
+			dishes: []ScoredDish{scored(1, 1, 100, 4), scored(2, 1, 100, 3), scored(3, 2, 100, 2), scored(4, 2, 100, 1)},
+			cfg:    with(func(c *config.Diversity) { c.MaxPerStore = 1; c.Window = 2 }),
+			want:   []uint{1, 3, 2, 4},
+		},
+		{
+			// 菜品 1 同时占用标签 a 和 b，只有标签 c 的菜品 4 不受限
+			name: "per-tag cap",
+			dishes: []ScoredDish{
+				scored(1, 1, 100, 5, "a", "b"), scored(2, 2, 100, 4, "a"), scored(3, 3, 100, 3, "b"), scored(4, 4, 100, 2, "c"),
+			},
+			cfg:  with(func(c *config.Diversity) { c.MaxPerTag = 1 }),
+			want: []uint{1, 4, 2, 3},
+		},
+		{
+			name: "long-tail slot",
+			dishes: []ScoredDish{
+				scored(1, 1, 100, 5), scored(2, 2, 100, 4), scored(3, 3, 100, 3), scored(4, 4, 100, 2), scored(5, 5, 2, 1),
+			},
+			cfg:         with(func(c *config.Diversity) { c.SerendipitySlot = 3; c.LongTailLikes = 5 }),
+			want:        []uint{1, 2, 5, 3, 4},
+			serendipity: 5,
+		},
+		{
+			name:   "long-tail slot without long-tail dishes",
+			dishes: []ScoredDish{scored(1, 1, 100, 3), scored(2, 2, 100, 2), scored(3, 3, 100, 1)},
+			cfg:    with(func(c *config.Diversity) { c.SerendipitySlot = 2; c.LongTailLikes = 5 }),
+			want:   []uint{1, 2, 3},
+		},
+		{
+			name: "long-tail slot respects caps",
+			dishes: []ScoredDish{
+				scored(1, 1, 100, 5), scored(2, 2, 100, 4), scored(3, 1, 1, 3), scored(4, 3, 1, 2),
+			},
+			cfg:         with(func(c *config.Diversity) { c.MaxPerStore = 1; c.SerendipitySlot = 3; c.LongTailLikes = 5 }),
+			want:        []uint{1, 2, 4, 3},
+			serendipity: 4,
+		},
+		{
+			// 第 2 位：菜品 2 为 0.5×0.99 − 0.5×1，菜品 3 为 0.5×0.9 − 0
+			name:   "mmr penalises same store",
+			dishes: []ScoredDish{scored(1, 1, 100, 10), scored(2, 1, 100, 9.9), scored(3, 2, 100, 9)},
+			cfg:    with(func(c *config.Diversity) { c.Lambda = 0.5; c.StoreWeight = 1 }),
+			want:   []uint{1, 3, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diversify(tt.dishes, tt.cfg)
+			ids := make([]uint, len(got))
+			for i, d := range got {
+				ids[i] = d.Dish.ID
+				isSerendipity := d.Reasons[0].Source == SourceSerendipity
+				if isSerendipity != (d.Dish.ID == tt.serendipity) {
+					t.Errorf("dish %d serendipity reason = %v", d.Dish.ID, isSerendipity)
+				}
+				if isSerendipity && len(d.Reasons) != maxReasons {
+					t.Errorf("dish %d has %d reasons, want %d", d.Dish.ID, len(d.Reasons), maxReasons)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("order = %v, want %v", ids, tt.want)
+			}
+			for _, d := range tt.dishes {
+				if d.Reasons[0].Source == SourceSerendipity {
+					t.Errorf("input dish %d reasons modified", d.Dish.ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package recommend
+
+import (
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Experiments 按用户所在的实验分组选择推荐策略，不在实验中的用户使用 recommend.model。
+// 各策略共享基础模型，Update、Reload 对每个基础模型只执行一次
+type Experiments struct {
+	experiments []config.Experiment
+	def         strategy
+	variants    map[string][]strategy // 实验 -> 与 Variants 下标对应的策略
+	models      modelSet
+}
+
+type strategy struct {
+	model      Recommender
+	maxResults int
+}
+
+func newExperiments(b *builder, experiments []config.Experiment) (*Experiments, error) {
+	// 基础模型按各策略中最大的 max_results 取候选，再由各策略截断
+	defMax := b.cfg.MaxResults
+	for _, e := range experiments {
+		for _, v := range e.Variants {
+			b.cfg.MaxResults = max(b.cfg.MaxResults, v.MaxResults)
+		}
+	}
+	def, err := b.strategy(b.cfg.Model, b.cfg.Blend, defMax)
+	if err != nil {
+		return nil, err
+	}
+	x := &Experiments{
+		experiments: experiments,
+		def:         strategy{model: def, maxResults: defMax},
+		variants:    make(map[string][]strategy, len(experiments)),
+	}
+	for _, e := range experiments {
+		for _, v := range e.Variants {
+			s, err := variantStrategy(b, v, defMax)
+			if err != nil {
+				return nil, fmt.Errorf("experiment %s variant %s: %w", e.Name, v.Name, err)
+			}
+			x.variants[e.Name] = append(x.variants[e.Name], s)
+		}
+	}
+	for _, name := range b.names {
+		x.models = append(x.models, namedModel{name: name, model: b.models[name]})
+	}
+	return x, nil
+}
+
+// variantStrategy 未设置的项沿用 recommend 下的配置，defMax 为 recommend.max_results
+func variantStrategy(b *builder, v config.ExperimentVariant, defMax int) (strategy, error) {
+	name, blend, maxResults := b.cfg.Model, b.cfg.Blend, defMax
+	if v.Model != "" {
+		name = v.Model
+	}
+	if v.Blend != nil {
+		blend = *v.Blend
+	}
+	if v.MaxResults > 0 {
+		maxResults = v.MaxResults
+	}
+	r, err := b.strategy(name, blend, maxResults)
+	if err != nil {
+		return strategy{}, err
+	}
+	return strategy{model: r, maxResults: maxResults}, nil
+}
+
+func (x *Experiments) Recommend(ctx context.Context, userID uint) ([]ScoredDish, error) {
+	s := x.def
+	if a, ok := utils.AssignExperiment(x.experiments, userID); ok {
+		s = x.variants[a.Experiment][a.Index]
+		experimentRequests.WithLabelValues(a.Experiment, a.Variant).Inc()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("experiment", a.Experiment), attribute.String("experiment.variant", a.Variant))
+	}
+	res, err := s.model.Recommend(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) > s.maxResults {
+		res = res[:s.maxResults]
+	}
+	return res, nil
+}
+
+func (x *Experiments) Update(ctx context.Context, save bool) (string, error) {
+	return x.models.Update(ctx, save)
+}
+
+func (x *Experiments) Reload(ctx context.Context) (bool, error) {
+	return x.models.Reload(ctx)
+}
+
+func (x *Experiments) Version() int64 {
+	return x.models.Version()
+}
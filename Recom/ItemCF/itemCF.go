@@ -1,119 +1,218 @@
 package recommend
 
 import (
-	"Food_recommendation/Basic/dao"
 	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
 	"context"
+	"go.opentelemetry.io/otel/attribute"
 	"math"
 	"sort"
-	"strings"
+	"sync/atomic"
+	"time"
 )
 
-func ItemCF(ctx context.Context, userID uint) ([]model.Dishes, error) {
-	history, err := dao.GetUserHistory(ctx, userID)
+// ScoredDish 带推荐得分和理由的菜品
+type ScoredDish struct {
+	Dish    model.Dishes
+	Score   float64
+	Reasons []Reason
+}
+
+// Neighbor 与某个菜品相似的菜品及相似度
+type Neighbor struct {
+	DishID uint
+	Sim    float64
+}
+
+// ItemCF 基于菜品的协同过滤：用点赞、评分和推荐结果中的点击、不感兴趣构建用户×菜品矩阵，
+// 计算菜品间（调整）余弦相似度，每个菜品只保留 Neighbors 个最相似的邻居，
+// 用户对菜品 j 的得分为 Σ sim(i, j) × r(u, i)，其中 i 为用户有过反馈的菜品，不感兴趣的 r 为负。
+// 用户点赞、评分过或标记不感兴趣的菜品和得分不为正的菜品不推荐。
+// 近邻表由 Update 离线计算并持久化，在线请求只查近邻打分
+type ItemCF struct {
+	data       Dataset
+	store      SnapshotStore
+	cfg        config.ItemCF
+	maxResults int
+	snap       atomic.Pointer[Snapshot]
+}
+
+// NewItemCF 创建协同过滤推荐器，需要先 Reload 或 Update 加载近邻表
+func NewItemCF(data Dataset, store SnapshotStore, cfg config.ItemCF, maxResults int) *ItemCF {
+	return &ItemCF{data: data, store: store, cfg: cfg, maxResults: maxResults}
+}
+
+// Recommend 返回用户按得分从高到低排序的推荐菜品，没有任何反馈的用户返回空列表
+func (cf *ItemCF) Recommend(ctx context.Context, userID uint) ([]ScoredDish, error) {
+	ctx, span := tracer.Start(ctx, "recommend.ItemCF")
+	defer span.End()
+	start := time.Now()
+	defer func() { computeDuration.WithLabelValues(ModelItemCF).Observe(time.Since(start).Seconds()) }()
+
+	snap := cf.snap.Load()
+	if snap == nil {
+		return nil, ErrNotReady
+	}
+	span.SetAttributes(attribute.Int64("neighbors.version", snap.Version))
+	profile, err := userProfile(ctx, cf.data, userID)
 	if err != nil {
 		return nil, err
 	}
-	// 获取所有菜品
-	allDishes, err := dao.GetAllDishes(ctx)
+
+	// 用户反馈过的菜品的邻居按相似度加权累加，贡献最大的反馈菜品作为推荐理由
+	scores := make(map[uint]float64)
+	refs := make(map[uint]uint)
+	best := make(map[uint]float64)
+	seen := consumed(profile)
+	for _, in := range profile {
+		r := value(in, cf.cfg)
+		if r == 0 {
+			continue
+		}
+		for _, n := range snap.Neighbors[in.DishID] {
+			c := n.Sim * r
+			scores[n.DishID] += c
+			if c > best[n.DishID] {
+				best[n.DishID] = c
+				refs[n.DishID] = in.DishID
+			}
+		}
+	}
+	ids := make([]uint, 0, len(scores))
+	for id, score := range scores {
+		if !seen[id] && score > 0 {
+			ids = append(ids, id)
+		}
+	}
+	dishes, names, err := dishesWithRefs(ctx, cf.data, ids, refs)
 	if err != nil {
 		return nil, err
 	}
-	allLike, err := dao.AllLike(ctx, userID)     // 返回用户喜欢的菜品集合
-	allSearch, err := dao.AllSearch(ctx, userID) // 返回用户搜索过的关键词集合
 
-	// 构建用户-物品矩阵
-	user := make(map[uint][]uint)
-	for _, h := range history {
-		user[h.UserID] = append(user[h.UserID], h.StoreID)
+	res := make([]ScoredDish, 0, len(dishes))
+	for _, dish := range dishes {
+		res = append(res, ScoredDish{
+			Dish:    dish,
+			Score:   scores[dish.ID],
+			Reasons: []Reason{likedReason(ModelItemCF, names[refs[dish.ID]])},
+		})
 	}
+	candidateCount.Observe(float64(len(res)))
+	span.SetAttributes(attribute.Int("candidates", len(res)))
 
-	// 构建物品-物品相似度矩阵
-	item := make(map[uint]map[uint]float64)
-	for _, userDishes := range user {
-		for _, i := range userDishes {
-			if item[i] == nil {
-				item[i] = make(map[uint]float64)
-			}
-			for _, j := range userDishes {
-				if i != j {
-					item[i][j]++
-				}
-			}
-		}
+	sortScored(res)
+	if len(res) > cf.maxResults {
+		res = res[:cf.maxResults]
 	}
+	return res, nil
+}
 
-	// 增加喜欢菜品的权重
-	like := 2.0
-	for _, likedDish := range allLike {
-		for _, relatedDish := range allDishes {
-			if likedDish.ID != relatedDish.ID {
-				if item[likedDish.ID] == nil {
-					item[likedDish.ID] = make(map[uint]float64)
-				}
-				item[likedDish.ID][relatedDish.ID] += like
-			}
-		}
+// value 点赞计 LikeWeight 分，评分计 Num 分，点击 n 次计 ClickWeight × log2(1+n) 分，
+// 不感兴趣扣 DismissWeight 分，各项相加；店铺浏览不计入
+func value(in Interaction, cfg config.ItemCF) float64 {
+	v := float64(in.Rating)
+	if in.Liked {
+		v += cfg.LikeWeight
 	}
+	if in.Clicks > 0 {
+		v += cfg.ClickWeight * math.Log2(1+float64(in.Clicks))
+	}
+	if in.Dismissed {
+		v -= cfg.DismissWeight
+	}
+	return v
+}
 
-	// 计算物品相似度
-	for i, similar := range item {
-		for j, count := range similar {
-			item[i][j] = count / math.Sqrt(float64(len(user[i])*len(user[j])))
+// sortScored 按得分从高到低排序，得分相同时按菜品 ID 排序保证结果稳定
+func sortScored(dishes []ScoredDish) {
+	sort.Slice(dishes, func(i, j int) bool {
+		if dishes[i].Score != dishes[j].Score {
+			return dishes[i].Score > dishes[j].Score
 		}
-	}
+		return dishes[i].Dish.ID < dishes[j].Dish.ID
+	})
+}
 
-	// 根据用户历史记录和物品相似度矩阵生成推荐列表
-	recon := make(map[uint]float64)
-	for _, dishID := range user[userID] {
-		for relate, similar := range item[dishID] {
-			recon[relate] += similar
+// matrix 用户×菜品矩阵。ratings 为原始分值，rows/cols 为计算相似度用的分值
+// （adjusted_cosine 时减去了用户平均分），分别按用户和菜品索引
+type matrix struct {
+	ratings map[uint]map[uint]float64
+	rows    map[uint]map[uint]float64
+	cols    map[uint]map[uint]float64
+	norms   map[uint]float64
+}
+
+func newMatrix(interactions []Interaction, cfg config.ItemCF, adjusted bool) *matrix {
+	m := &matrix{
+		ratings: make(map[uint]map[uint]float64),
+		rows:    make(map[uint]map[uint]float64),
+		cols:    make(map[uint]map[uint]float64),
+		norms:   make(map[uint]float64),
+	}
+	for _, in := range interactions {
+		v := value(in, cfg)
+		if v == 0 {
+			continue
+		}
+		if m.ratings[in.UserID] == nil {
+			m.ratings[in.UserID] = make(map[uint]float64)
 		}
+		m.ratings[in.UserID][in.DishID] = v
 	}
 
-	// 关键词匹配，增加推荐得分
-	keyW := 1.0
-	for _, dish := range allDishes {
-		for _, keyword := range allSearch {
-			if strings.Contains(dish.Name, keyword) {
-				recon[dish.ID] += keyW
+	for u, row := range m.ratings {
+		var mean float64
+		if adjusted {
+			for _, v := range row {
+				mean += v
+			}
+			mean /= float64(len(row))
+		}
+		m.rows[u] = make(map[uint]float64, len(row))
+		for i, v := range row {
+			v -= mean
+			if v == 0 {
+				continue
+			}
+			m.rows[u][i] = v
+			if m.cols[i] == nil {
+				m.cols[i] = make(map[uint]float64)
 			}
+			m.cols[i][u] = v
+			m.norms[i] += v * v
 		}
 	}
-
-	// 排序并返回推荐结果
-	type RecommendItem struct {
-		DishID uint
-		Score  float64
+	for i, n := range m.norms {
+		m.norms[i] = math.Sqrt(n)
 	}
+	return m
+}
 
-	// 根据推荐得分排序
-	var recommendList []RecommendItem
-	for dishID, score := range recon {
-		recommendList = append(recommendList, RecommendItem{
-			DishID: dishID,
-			Score:  score,
-		})
+// neighbors 返回与菜品 i 相似度为正的前 k 个菜品，只遍历与 i 有共同用户的菜品
+func (m *matrix) neighbors(i uint, k int) []Neighbor {
+	dots := make(map[uint]float64)
+	for u, vi := range m.cols[i] {
+		for j, vj := range m.rows[u] {
+			if j != i {
+				dots[j] += vi * vj
+			}
+		}
 	}
 
-	// 按得分从高到低排序
-	sort.Slice(recommendList, func(i, j int) bool {
-		return recommendList[i].Score > recommendList[j].Score
-	})
-
-	// 取Top N推荐结果
-	var res []model.Dishes
-	for i, item := range recommendList {
-		if i >= 100 {
-			break
+	res := make([]Neighbor, 0, len(dots))
+	for j, dot := range dots {
+		if sim := dot / (m.norms[i] * m.norms[j]); sim > 0 {
+			res = append(res, Neighbor{DishID: j, Sim: sim})
 		}
-		for _, dish := range allDishes {
-			if uint(dish.ID) == item.DishID {
-				res = append(res, dish)
-				break
-			}
+	}
+	sort.Slice(res, func(a, b int) bool {
+		if res[a].Sim != res[b].Sim {
+			return res[a].Sim > res[b].Sim
 		}
+		return res[a].DishID < res[b].DishID
+	})
+	if len(res) > k {
+		res = res[:k]
 	}
-
-	return res, nil
+	return res
 }
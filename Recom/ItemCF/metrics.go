@@ -0,0 +1,52 @@
+package recommend
+
+import (
+	"Food_recommendation/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+)
+
+// tracer 推荐计算的链路追踪
+var tracer = otel.Tracer("Food_recommendation/Recom/ItemCF")
+
+var (
+	computeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_compute_seconds",
+		Help:      "Time spent scoring recommendations for one user by model.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"model"})
+	candidateCount = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_candidates",
+		Help:      "Number of scored candidate dishes per recommendation.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+	})
+	matrixItems = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_matrix_items",
+		Help:      "Dishes with neighbors in the active neighbor snapshot.",
+	})
+	matrixEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_matrix_entries",
+		Help:      "Neighbor pairs in the active neighbor snapshot.",
+	})
+	precomputeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_precompute_seconds",
+		Help:      "Time spent computing a model (ItemCF neighbor snapshot or ALS training).",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"model"})
+	modelVersion = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_model_version",
+		Help:      "Version of the model in use (ItemCF neighbor snapshot or ALS model file).",
+	}, []string{"model"})
+	experimentRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: utils.MetricsNamespace,
+		Name:      "recommend_experiment_requests_total",
+		Help:      "Recommendations computed for users in an experiment, by experiment and variant.",
+	}, []string{"experiment", "variant"})
+)
@@ -0,0 +1,154 @@
+package recommend
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Snapshot 某个批次的菜品近邻表，每个菜品的邻居按相似度从高到低排列
+type Snapshot struct {
+	Version   int64
+	Neighbors map[uint][]Neighbor
+}
+
+// Entries 近邻表中的邻居总数
+func (s *Snapshot) Entries() int {
+	n := 0
+	for _, ns := range s.Neighbors {
+		n += len(ns)
+	}
+	return n
+}
+
+// SnapshotStore 近邻表的持久化，DBSnapshotStore 为数据库实现
+type SnapshotStore interface {
+	// ActiveVersion 返回当前生效的批次号，没有时返回 0
+	ActiveVersion(ctx context.Context) (int64, error)
+	// Load 读取当前生效的近邻表，没有时返回 nil
+	Load(ctx context.Context) (*Snapshot, error)
+	// Save 写入新批次并切换为生效批次，回填 snap.Version
+	Save(ctx context.Context, snap *Snapshot, cfg config.ItemCF) error
+}
+
+// DBSnapshotStore 把近邻表存入 dish_similarity 表
+type DBSnapshotStore struct{}
+
+func (DBSnapshotStore) ActiveVersion(ctx context.Context) (int64, error) {
+	v, err := dao.ActiveSimilarityVersion(ctx)
+	if err != nil || v == nil {
+		return 0, err
+	}
+	return v.Version, nil
+}
+
+func (DBSnapshotStore) Load(ctx context.Context) (*Snapshot, error) {
+	v, err := dao.ActiveSimilarityVersion(ctx)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	rows, err := dao.DishSimilarities(ctx, v.Version)
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{Version: v.Version, Neighbors: make(map[uint][]Neighbor, v.Dishes)}
+	for _, r := range rows {
+		snap.Neighbors[r.DishID] = append(snap.Neighbors[r.DishID], Neighbor{DishID: r.NeighborID, Sim: r.Sim})
+	}
+	return snap, nil
+}
+
+func (DBSnapshotStore) Save(ctx context.Context, snap *Snapshot, cfg config.ItemCF) error {
+	rows := make([]model.DishSimilarity, 0, snap.Entries())
+	for dishID, ns := range snap.Neighbors {
+		for _, n := range ns {
+			rows = append(rows, model.DishSimilarity{DishID: dishID, NeighborID: n.DishID, Sim: n.Sim})
+		}
+	}
+	meta := &model.SimilarityVersion{
+		Similarity: cfg.Similarity,
+		Neighbors:  cfg.Neighbors,
+		Dishes:     len(snap.Neighbors),
+		Entries:    len(rows),
+	}
+	if err := dao.SaveDishSimilarity(ctx, meta, rows); err != nil {
+		return err
+	}
+	snap.Version = meta.Version
+	return nil
+}
+
+// Build 读取全部点赞、评分和推荐反馈，计算每个菜品的前 Neighbors 个近邻，不写入存储
+func (cf *ItemCF) Build(ctx context.Context) (*Snapshot, error) {
+	ctx, span := tracer.Start(ctx, "recommend.BuildNeighbors")
+	defer span.End()
+	start := time.Now()
+	defer func() { precomputeDuration.WithLabelValues(ModelItemCF).Observe(time.Since(start).Seconds()) }()
+
+	interactions, err := cf.data.Interactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := newMatrix(interactions, cf.cfg, cf.cfg.Similarity == "adjusted_cosine")
+	snap := &Snapshot{Neighbors: make(map[uint][]Neighbor, len(m.cols))}
+	for i := range m.cols {
+		if ns := m.neighbors(i, cf.cfg.Neighbors); len(ns) > 0 {
+			snap.Neighbors[i] = ns
+		}
+	}
+	return snap, nil
+}
+
+// Update 计算近邻表，save 时持久化为新批次并在本实例立即生效
+func (cf *ItemCF) Update(ctx context.Context, save bool) (string, error) {
+	snap, err := cf.Build(ctx)
+	if err != nil {
+		return "", err
+	}
+	if save {
+		if err := cf.store.Save(ctx, snap, cf.cfg); err != nil {
+			return "", err
+		}
+		cf.swap(snap)
+	}
+	return fmt.Sprintf("%d dishes, %d neighbor pairs", len(snap.Neighbors), snap.Entries()), nil
+}
+
+// Reload 存储中的生效批次与本实例不同时加载并切换，返回是否发生了切换
+func (cf *ItemCF) Reload(ctx context.Context) (bool, error) {
+	version, err := cf.store.ActiveVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+	if version == 0 {
+		return false, nil
+	}
+	if cur := cf.snap.Load(); cur != nil && cur.Version == version {
+		return false, nil
+	}
+	snap, err := cf.store.Load(ctx)
+	if err != nil || snap == nil {
+		return false, err
+	}
+	cf.swap(snap)
+	return true, nil
+}
+
+// swap 原子替换近邻表
+func (cf *ItemCF) swap(snap *Snapshot) {
+	cf.snap.Store(snap)
+	modelVersion.WithLabelValues(ModelItemCF).Set(float64(snap.Version))
+	matrixItems.Set(float64(len(snap.Neighbors)))
+	matrixEntries.Set(float64(snap.Entries()))
+}
+
+// Version 返回当前生效的近邻表批次，未加载时返回 0
+func (cf *ItemCF) Version() int64 {
+	if snap := cf.snap.Load(); snap != nil {
+		return snap.Version
+	}
+	return 0
+}
@@ -0,0 +1,126 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+// Popular 热门菜品推荐：不区分用户，按点赞数和评分取前 maxResults 个，排名越靠前得分越高。
+// 用于冷启动用户和其他模型候选不足时补位，用户点赞、评分过或标记不感兴趣的菜品不再推荐
+type Popular struct {
+	data       Dataset
+	maxResults int
+	list       atomic.Pointer[popularList]
+}
+
+type popularList struct {
+	version     int64
+	fingerprint uint64
+	ids         []uint
+}
+
+// NewPopular 创建热门菜品推荐器，需要先 Reload 或 Update 加载榜单
+func NewPopular(data Dataset, maxResults int) *Popular {
+	return &Popular{data: data, maxResults: maxResults}
+}
+
+func (p *Popular) Recommend(ctx context.Context, userID uint) ([]ScoredDish, error) {
+	ctx, span := tracer.Start(ctx, "recommend.Popular")
+	defer span.End()
+	start := time.Now()
+	defer func() { computeDuration.WithLabelValues(ModelPopular).Observe(time.Since(start).Seconds()) }()
+
+	list := p.list.Load()
+	if list == nil {
+		return nil, ErrNotReady
+	}
+	span.SetAttributes(attribute.Int64("model.version", list.version))
+	profile, err := userProfile(ctx, p.data, userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := consumed(profile)
+
+	ids := make([]uint, 0, len(list.ids))
+	scores := make(map[uint]float64, len(list.ids))
+	for rank, id := range list.ids {
+		if !seen[id] {
+			ids = append(ids, id)
+			scores[id] = 1 - float64(rank)/float64(len(list.ids))
+		}
+	}
+	dishes, err := p.data.Dishes(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ScoredDish, 0, len(dishes))
+	for _, dish := range dishes {
+		res = append(res, ScoredDish{Dish: dish, Score: scores[dish.ID], Reasons: []Reason{popularReason(ModelPopular)}})
+	}
+	candidateCount.Observe(float64(len(res)))
+	span.SetAttributes(attribute.Int("candidates", len(res)))
+
+	sortScored(res)
+	if len(res) > p.maxResults {
+		res = res[:p.maxResults]
+	}
+	return res, nil
+}
+
+// Update 重新查询热门榜单，save 时在本实例立即生效（榜单只在内存中，无需持久化）
+func (p *Popular) Update(ctx context.Context, save bool) (string, error) {
+	list, err := p.build(ctx)
+	if err != nil {
+		return "", err
+	}
+	if save {
+		p.swap(list)
+	}
+	return fmt.Sprintf("%d dishes", len(list.ids)), nil
+}
+
+// Reload 重新查询榜单，排名没有变化时不切换
+func (p *Popular) Reload(ctx context.Context) (bool, error) {
+	list, err := p.build(ctx)
+	if err != nil {
+		return false, err
+	}
+	if cur := p.list.Load(); cur != nil && cur.fingerprint == list.fingerprint {
+		return false, nil
+	}
+	p.swap(list)
+	return true, nil
+}
+
+func (p *Popular) swap(list *popularList) {
+	p.list.Store(list)
+	modelVersion.WithLabelValues(ModelPopular).Set(float64(list.version))
+}
+
+func (p *Popular) Version() int64 {
+	if list := p.list.Load(); list != nil {
+		return list.version
+	}
+	return 0
+}
+
+// build 多取一倍，过滤掉用户点赞、评分过或标记不感兴趣的菜品后仍能凑满
+func (p *Popular) build(ctx context.Context) (*popularList, error) {
+	dishes, err := p.data.Popular(ctx, 2*p.maxResults)
+	if err != nil {
+		return nil, err
+	}
+	list := &popularList{version: time.Now().UnixMilli(), ids: make([]uint, len(dishes))}
+	h := fnv.New64a()
+	for i, d := range dishes {
+		list.ids[i] = d.ID
+		fmt.Fprintf(h, "%d\n", d.ID)
+	}
+	list.fingerprint = h.Sum64()
+	return list, nil
+}
@@ -0,0 +1,78 @@
+package recommend
+
+import (
+	"Food_recommendation/Basic/model"
+	"context"
+)
+
+// Reason 推荐理由，Source 为产生理由的模型，Text 为展示给用户的文案
+type Reason struct {
+	Source string
+	Text   string
+}
+
+func likedReason(source, dish string) Reason {
+	if dish == "" {
+		return Reason{Source: source, Text: "与你喜欢的菜品相似"}
+	}
+	return Reason{Source: source, Text: "因为你喜欢「" + dish + "」"}
+}
+
+func searchReason(source, keyword string) Reason {
+	return Reason{Source: source, Text: "与你搜索的「" + keyword + "」相关"}
+}
+
+func tagReason(source, tag string) Reason {
+	return Reason{Source: source, Text: "符合你偏爱的「" + tag + "」"}
+}
+
+func storeReason(source, store string) Reason {
+	if store == "" {
+		return Reason{Source: source, Text: "来自你常去的店铺"}
+	}
+	return Reason{Source: source, Text: "来自你常去的「" + store + "」"}
+}
+
+func priceReason(source string) Reason {
+	return Reason{Source: source, Text: "价位与你常点的相近"}
+}
+
+func popularReason(source string) Reason {
+	return Reason{Source: source, Text: "大家都在点"}
+}
+
+func serendipityReason() Reason {
+	return Reason{Source: SourceSerendipity, Text: "换换口味，发现小众好菜"}
+}
+
+// dishesWithRefs 查询候选菜品，同时查出推荐理由中引用的菜品（refs 为候选 -> 引用菜品）的名称。
+// 只返回 ids 中的菜品，引用的菜品已下架时名称为空
+func dishesWithRefs(ctx context.Context, data Dataset, ids []uint, refs map[uint]uint) ([]model.Dishes, map[uint]string, error) {
+	want := make(map[uint]bool, len(ids))
+	query := make([]uint, 0, len(ids)+len(refs))
+	for _, id := range ids {
+		want[id] = true
+		query = append(query, id)
+	}
+	queried := make(map[uint]bool)
+	for _, id := range ids {
+		if ref, ok := refs[id]; ok && !want[ref] && !queried[ref] {
+			queried[ref] = true
+			query = append(query, ref)
+		}
+	}
+	dishes, err := data.Dishes(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[uint]string, len(dishes))
+	res := make([]model.Dishes, 0, len(ids))
+	for _, d := range dishes {
+		names[d.ID] = d.Name
+		if want[d.ID] {
+			res = append(res, d)
+		}
+	}
+	return res, names, nil
+}
@@ -0,0 +1,264 @@
+package recommend
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/config"
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// 推荐模型名称，对应 recommend.model
+const (
+	ModelItemCF  = "itemcf"
+	ModelALS     = "als"
+	ModelContent = "content"
+	ModelSearch  = "search"
+	ModelPopular = "popular"
+	ModelBlend   = "blend"
+)
+
+// ErrNotReady 模型尚未加载（首次离线计算未完成）
+var ErrNotReady = errors.New("recommend model not loaded yet")
+
+// Recommender 推荐模型的公共接口，模型需要离线计算，在线请求只做查表打分
+type Recommender interface {
+	// Recommend 返回用户按得分从高到低排序的推荐菜品，模型未加载时返回 ErrNotReady
+	Recommend(ctx context.Context, userID uint) ([]ScoredDish, error)
+	// Update 重新计算模型，save 时持久化并在本实例立即生效，返回可读的统计信息
+	Update(ctx context.Context, save bool) (string, error)
+	// Reload 存储中有新版本时加载并切换，返回是否发生了切换
+	Reload(ctx context.Context) (bool, error)
+	// Version 返回当前生效的模型版本，未加载时返回 0
+	Version() int64
+}
+
+// engine 在线请求使用的推荐模型
+var engine Recommender
+
+// Init 设置在线请求使用的推荐模型
+func Init(r Recommender) {
+	engine = r
+}
+
+// Recommend 使用 Init 设置的推荐模型返回用户的推荐菜品
+func Recommend(ctx context.Context, userID uint) ([]ScoredDish, error) {
+	ctx, span := tracer.Start(ctx, "recommend.Recommend", trace.WithAttributes(attribute.Int64("user.id", int64(userID))))
+	defer span.End()
+	dishes, err := engine.Recommend(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return dishes, nil
+}
+
+// New 按 recommend.model 创建推荐模型，有启用的实验时按用户分组选择策略。ItemCF 的近邻表存入数据库
+func New(cfg config.Recommend, experiments []config.Experiment, data Dataset) (Recommender, error) {
+	return NewWithStore(cfg, experiments, data, DBSnapshotStore{})
+}
+
+// NewWithStore 与 New 相同，ItemCF 的近邻表存入 store（离线评估时使用内存存储）
+func NewWithStore(cfg config.Recommend, experiments []config.Experiment, data Dataset, store SnapshotStore) (Recommender, error) {
+	var enabled []config.Experiment
+	for _, e := range experiments {
+		if e.Enabled {
+			enabled = append(enabled, e)
+		}
+	}
+	b := &builder{cfg: cfg, data: data, store: store, models: make(map[string]Recommender)}
+	if len(enabled) == 0 {
+		return b.strategy(cfg.Model, cfg.Blend, cfg.MaxResults)
+	}
+	return newExperiments(b, enabled)
+}
+
+// builder 创建推荐策略，同名的基础模型只创建一次，由各策略共享
+type builder struct {
+	cfg    config.Recommend
+	data   Dataset
+	store  SnapshotStore
+	models map[string]Recommender
+	names  []string // 按创建顺序排列的基础模型名称
+}
+
+// strategy 创建 name 对应的策略，blend 时按 blend 混合基础模型
+func (b *builder) strategy(name string, blend config.Blend, maxResults int) (Recommender, error) {
+	if name != ModelBlend {
+		return b.model(name)
+	}
+	models := make(map[string]Recommender, len(blend.Sources))
+	for _, src := range blend.Sources {
+		m, err := b.model(src.Model)
+		if err != nil {
+			return nil, err
+		}
+		models[src.Model] = m
+	}
+	return NewBlend(blend, models, maxResults)
+}
+
+func (b *builder) model(name string) (Recommender, error) {
+	if m, ok := b.models[name]; ok {
+		return m, nil
+	}
+	m, err := newModel(name, b.cfg, b.data, b.store)
+	if err != nil {
+		return nil, err
+	}
+	b.models[name] = m
+	b.names = append(b.names, name)
+	return m, nil
+}
+
+type namedModel struct {
+	name  string
+	model Recommender
+}
+
+// modelSet 一组基础模型，Update、Reload 依次调用，某个模型失败不影响其他模型
+type modelSet []namedModel
+
+func (ms modelSet) Update(ctx context.Context, save bool) (string, error) {
+	var stats []string
+	var errs []error
+	for _, m := range ms {
+		s, err := m.model.Update(ctx, save)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+			continue
+		}
+		stats = append(stats, m.name+": "+s)
+	}
+	return strings.Join(stats, "; "), errors.Join(errs...)
+}
+
+// Reload 任一模型切换了版本即返回 true
+func (ms modelSet) Reload(ctx context.Context) (bool, error) {
+	changed := false
+	var errs []error
+	for _, m := range ms {
+		c, err := m.model.Reload(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+		}
+		changed = changed || c
+	}
+	return changed, errors.Join(errs...)
+}
+
+// Version 返回最旧的版本，有模型未加载时返回 0
+func (ms modelSet) Version() int64 {
+	var v int64
+	for i, m := range ms {
+		mv := m.model.Version()
+		if mv == 0 {
+			return 0
+		}
+		if i == 0 || mv < v {
+			v = mv
+		}
+	}
+	return v
+}
+
+func newModel(name string, cfg config.Recommend, data Dataset, store SnapshotStore) (Recommender, error) {
+	switch name {
+	case ModelItemCF:
+		return NewItemCF(data, store, cfg.ItemCF, cfg.MaxResults), nil
+	case ModelALS:
+		return NewALS(data, cfg.ALS, cfg.MaxResults), nil
+	case ModelContent:
+		return NewContent(data, cfg.Content, cfg.MaxResults), nil
+	case ModelSearch:
+		return NewSearch(data, cfg.MaxResults), nil
+	case ModelPopular:
+		return NewPopular(data, cfg.MaxResults), nil
+	default:
+		return nil, fmt.Errorf("unknown recommend model %q", name)
+	}
+}
+
+// Lease 多实例部署时选出执行定时重算的实例，Acquire 成功表示本实例持有（或续期了）租约
+type Lease interface {
+	Acquire(ctx context.Context) (bool, error)
+}
+
+// precomputeLease 定时重算使用的租约名
+const precomputeLease = "recommend_precompute"
+
+// DBLease 基于 leases 表的租约，TTL 内持有者不续期，其他实例才能接管
+type DBLease struct {
+	Holder string
+	TTL    time.Duration
+}
+
+// NewDBLease 以主机名和进程号作为持有者标识
+func NewDBLease(ttl time.Duration) DBLease {
+	host, _ := os.Hostname()
+	return DBLease{Holder: fmt.Sprintf("%s:%d", host, os.Getpid()), TTL: ttl}
+}
+
+func (l DBLease) Acquire(ctx context.Context) (bool, error) {
+	return dao.AcquireLease(ctx, precomputeLease, l.Holder, l.TTL)
+}
+
+// Run 启动时加载模型，存储中还没有时立即计算一次；之后按 Reload 间隔检查新版本，
+// Interval 大于 0 时按该间隔重算。重算只在持有 lease 的实例上执行，其他实例通过 Reload 加载结果。
+// ctx 取消后退出
+func Run(ctx context.Context, r Recommender, p config.Precompute, lease Lease) {
+	if _, err := r.Reload(ctx); err != nil {
+		slog.ErrorContext(ctx, "load recommend model failed", "err", err)
+	}
+	if r.Version() == 0 && p.Interval > 0 {
+		update(ctx, r, lease)
+	}
+
+	reload := time.NewTicker(p.Reload)
+	defer reload.Stop()
+	var rebuild <-chan time.Time
+	if p.Interval > 0 {
+		t := time.NewTicker(p.Interval)
+		defer t.Stop()
+		rebuild = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload.C:
+			if changed, err := r.Reload(ctx); err != nil {
+				slog.ErrorContext(ctx, "reload recommend model failed", "err", err)
+			} else if changed {
+				slog.InfoContext(ctx, "recommend model reloaded", "version", r.Version())
+			}
+		case <-rebuild:
+			update(ctx, r, lease)
+		}
+	}
+}
+
+func update(ctx context.Context, r Recommender, lease Lease) {
+	if ok, err := lease.Acquire(ctx); err != nil {
+		slog.ErrorContext(ctx, "acquire precompute lease failed", "err", err)
+		return
+	} else if !ok {
+		slog.DebugContext(ctx, "precompute lease held by another instance, skip update")
+		return
+	}
+	start := time.Now()
+	stats, err := r.Update(ctx, true)
+	if err != nil {
+		slog.ErrorContext(ctx, "update recommend model failed", "err", err)
+		return
+	}
+	slog.InfoContext(ctx, "recommend model updated", "version", r.Version(), "stats", stats, "elapsed", time.Since(start).String())
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/Recom/eval"
+	"Food_recommendation/config"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+const evalUsage = `usage: recom [-config path] eval [flags] [variant [variant]]
+
+Evaluate recommendation models offline. Likes, ratings, store histories,
+searches and clicks or dismissals of recommended dishes are split by time
+(impressions and dwell events are exported but not used): models are trained
+on the earlier part, and the dishes each user liked or rated at least
+-min-rating afterwards (and had not liked or rated before) are what they
+should have recommended.
+
+A variant is a recommend.model value optionally followed by overrides of its
+config section (item_cf, als, content or blend), for example
+  itemcf:similarity=adjusted_cosine,neighbors=50
+  als:factors=64,iterations=20
+  content:price_bands=[30,60],keyword_weight=0
+Without variants recommend.model is evaluated. Two variants are compared side
+by side.
+
+flags:
+  -input path    read interactions from a .csv or .jsonl export instead of
+                 the database (dishes are then known only by id and store)
+  -export path   write the interactions and recommendation events in the
+                 database to a .csv or .jsonl file and exit
+  -train 0.8     fraction of interactions (oldest first) used for training
+  -cutoff time   train on interactions before this RFC 3339 time instead
+  -k 10          length of the evaluated recommendation list
+  -min-rating 4  lowest rating that counts as liking a dish
+  -json          print the results as JSON
+`
+
+// runEval 执行 eval 子命令，返回进程退出码
+func runEval(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, evalUsage) }
+	input := fs.String("input", "", "interaction export to read")
+	export := fs.String("export", "", "write interactions to this file and exit")
+	train := fs.Float64("train", 0.8, "fraction of interactions used for training")
+	cutoff := fs.String("cutoff", "", "train on interactions before this time")
+	k := fs.Int("k", 10, "recommendation list length")
+	minRating := fs.Uint("min-rating", 4, "lowest relevant rating")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 2 || *k <= 0 || (*input != "" && *export != "") {
+		fs.Usage()
+		return 2
+	}
+	specs := fs.Args()
+	if len(specs) == 0 {
+		specs = []string{cfg.Recommend.Model}
+	}
+	variants := make([]eval.Variant, 0, len(specs))
+	for _, spec := range specs {
+		v, err := eval.ParseVariant(spec, *cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		variants = append(variants, v)
+	}
+
+	ctx := context.Background()
+	var events []eval.Event
+	var catalog []model.Dishes
+	if *input != "" {
+		var err error
+		if events, err = eval.ReadFile(*input); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		catalog = eval.CatalogFromEvents(events)
+	} else {
+		if err := openDB(cfg.Database); err != nil {
+			fmt.Fprintln(os.Stderr, "database schema not ready:", err)
+			return 1
+		}
+		var err error
+		if events, err = eval.LoadDB(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if *export != "" {
+			if err := eval.WriteFile(*export, events); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			fmt.Printf("%d interactions written to %s\n", len(events), *export)
+			return 0
+		}
+		if catalog, err = eval.Catalog(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	events = eval.TrainingEvents(events)
+	var trainSet, testSet []eval.Event
+	var at time.Time
+	if *cutoff != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, *cutoff); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid -cutoff:", err)
+			return 2
+		}
+		trainSet, testSet = eval.SplitAt(events, at)
+	} else {
+		var err error
+		if trainSet, testSet, at, err = eval.Split(events, *train); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	relevant := eval.Relevant(trainSet, testSet, *minRating)
+	if len(trainSet) == 0 || len(relevant) == 0 {
+		fmt.Fprintf(os.Stderr, "cutoff %s leaves %d training interactions and %d test users\n", at.Format(time.RFC3339), len(trainSet), len(relevant))
+		return 1
+	}
+	if !*asJSON {
+		fmt.Printf("%d interactions, cutoff %s: %d train, %d test, %d users to evaluate, %d dishes\n\n",
+			len(events), at.Format(time.RFC3339), len(trainSet), len(testSet), len(relevant), len(catalog))
+	}
+
+	data := eval.NewDataset(trainSet, catalog)
+	opts := eval.Options{K: *k, MinRating: *minRating}
+	results := make([]eval.Metrics, 0, len(variants))
+	for _, v := range variants {
+		m, err := eval.Run(ctx, v, data, relevant, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		results = append(results, m)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	if err := eval.WriteReport(os.Stdout, results, *k); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,221 @@
+package eval
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	recommend "Food_recommendation/Recom/ItemCF"
+	"Food_recommendation/config"
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// maxKeywords 每个用户取最近的搜索关键词数，与 dao.AllSearch 一致
+const maxKeywords = 20
+
+// Split 按时间切分行为：最早的 fraction 作为训练集，其余作为测试集，返回切分时间。
+// 同一时刻的行为归入同一侧
+func Split(events []Event, fraction float64) (train, test []Event, cutoff time.Time, err error) {
+	if fraction <= 0 || fraction >= 1 {
+		return nil, nil, time.Time{}, errors.New("train fraction must be between 0 and 1")
+	}
+	if len(events) == 0 {
+		return nil, nil, time.Time{}, errors.New("no interactions to evaluate")
+	}
+	sorted := append([]Event(nil), events...)
+	sortEvents(sorted)
+	cutoff = sorted[int(fraction*float64(len(sorted)))].Time
+	train, test = SplitAt(sorted, cutoff)
+	return train, test, cutoff, nil
+}
+
+// SplitAt 早于 cutoff 的行为作为训练集，其余作为测试集
+func SplitAt(events []Event, cutoff time.Time) (train, test []Event) {
+	for _, e := range events {
+		if e.Time.Before(cutoff) {
+			train = append(train, e)
+		} else {
+			test = append(test, e)
+		}
+	}
+	return train, test
+}
+
+// Catalog 返回数据库中所有可推荐的菜品
+func Catalog(ctx context.Context) ([]model.Dishes, error) {
+	return dao.CatalogDishes(ctx)
+}
+
+// CatalogFromEvents 没有数据库时由行为中出现过的菜品构造目录，只有 ID 和店铺，
+// 基于内容的模型只能用到店铺特征
+func CatalogFromEvents(events []Event) []model.Dishes {
+	stores := make(map[uint]uint)
+	for _, e := range events {
+		if e.DishID == 0 {
+			continue
+		}
+		if _, ok := stores[e.DishID]; !ok || e.StoreID != 0 {
+			stores[e.DishID] = e.StoreID
+		}
+	}
+	dishes := make([]model.Dishes, 0, len(stores))
+	for id, storeID := range stores {
+		dishes = append(dishes, model.Dishes{ID: id, StoreID: storeID, Available: true, Store: model.Store{ID: storeID, Active: true}})
+	}
+	sort.Slice(dishes, func(i, j int) bool { return dishes[i].ID < dishes[j].ID })
+	return dishes
+}
+
+// Dataset 由训练集构造的推荐数据源，实现 recommend.Dataset。热门菜品按训练集内的点赞数和平均评分排序，
+// 不使用菜品表中包含测试期数据的统计
+type Dataset struct {
+	interactions []recommend.Interaction
+	byUser       map[uint][]recommend.Interaction
+	catalog      []model.Dishes
+	dishes       map[uint]model.Dishes
+	keywords     map[uint][]string
+	popular      []model.Dishes
+}
+
+// NewDataset 由训练集和菜品目录构造数据源，浏览按目录展开到店内菜品
+func NewDataset(train []Event, catalog []model.Dishes) *Dataset {
+	d := &Dataset{
+		byUser:   make(map[uint][]recommend.Interaction),
+		catalog:  catalog,
+		dishes:   make(map[uint]model.Dishes, len(catalog)),
+		keywords: make(map[uint][]string),
+	}
+	storeDishes := make(map[uint][]uint)
+	for _, dish := range catalog {
+		d.dishes[dish.ID] = dish
+		storeDishes[dish.StoreID] = append(storeDishes[dish.StoreID], dish.ID)
+	}
+
+	var likes []model.Like
+	var ratings []model.Rating
+	visits := make(map[[2]uint]uint)
+	signals := make(map[[2]uint]dao.EventSignal)
+	likeNum := make(map[uint]int)
+	ratingSum := make(map[uint]float64)
+	ratingNum := make(map[uint]int)
+	for _, e := range train {
+		switch e.Type {
+		case EventLike:
+			likes = append(likes, model.Like{UserID: e.UserID, DishID: e.DishID})
+			likeNum[e.DishID]++
+		case EventRating:
+			ratings = append(ratings, model.Rating{UserID: e.UserID, DishID: e.DishID, Num: e.Rating})
+			ratingSum[e.DishID] += float64(e.Rating)
+			ratingNum[e.DishID]++
+		case EventHistory:
+			visits[[2]uint{e.UserID, e.StoreID}]++
+		case EventSearch:
+			d.keywords[e.UserID] = append(d.keywords[e.UserID], e.Keyword)
+		case EventClick, EventDismiss:
+			s := signals[[2]uint{e.UserID, e.DishID}]
+			if e.Type == EventClick {
+				s.Clicks++
+			} else {
+				s.Dismisses++
+			}
+			signals[[2]uint{e.UserID, e.DishID}] = s
+		}
+	}
+	storeVisits := make([]dao.StoreVisit, 0, len(visits))
+	for k, n := range visits {
+		storeVisits = append(storeVisits, dao.StoreVisit{UserID: k[0], StoreID: k[1], Visits: n})
+	}
+	eventSignals := make([]dao.EventSignal, 0, len(signals))
+	for k, s := range signals {
+		s.UserID, s.DishID = k[0], k[1]
+		eventSignals = append(eventSignals, s)
+	}
+	// 训练集按时间排序，后出现的评分覆盖先前的评分
+	d.interactions = recommend.MergeInteractions(likes, ratings, storeVisits, eventSignals, storeDishes)
+	for _, in := range d.interactions {
+		d.byUser[in.UserID] = append(d.byUser[in.UserID], in)
+	}
+	// 关键词按时间倒序，只保留最近的 maxKeywords 个
+	for u, kws := range d.keywords {
+		rev := make([]string, 0, min(len(kws), maxKeywords))
+		for i := len(kws) - 1; i >= 0 && len(rev) < maxKeywords; i-- {
+			rev = append(rev, kws[i])
+		}
+		d.keywords[u] = rev
+	}
+
+	d.popular = append([]model.Dishes(nil), catalog...)
+	avg := func(id uint) float64 {
+		if ratingNum[id] == 0 {
+			return 0
+		}
+		return ratingSum[id] / float64(ratingNum[id])
+	}
+	sort.SliceStable(d.popular, func(i, j int) bool {
+		a, b := d.popular[i].ID, d.popular[j].ID
+		if likeNum[a] != likeNum[b] {
+			return likeNum[a] > likeNum[b]
+		}
+		if avg(a) != avg(b) {
+			return avg(a) > avg(b)
+		}
+		return a < b
+	})
+	return d
+}
+
+func (d *Dataset) Interactions(context.Context) ([]recommend.Interaction, error) {
+	return d.interactions, nil
+}
+
+func (d *Dataset) UserInteractions(_ context.Context, userID uint) ([]recommend.Interaction, error) {
+	return d.byUser[userID], nil
+}
+
+func (d *Dataset) Dishes(_ context.Context, ids []uint) ([]model.Dishes, error) {
+	res := make([]model.Dishes, 0, len(ids))
+	for _, id := range ids {
+		if dish, ok := d.dishes[id]; ok {
+			res = append(res, dish)
+		}
+	}
+	return res, nil
+}
+
+func (d *Dataset) Catalog(context.Context) ([]model.Dishes, error) {
+	return d.catalog, nil
+}
+
+func (d *Dataset) Keywords(_ context.Context, userID uint) ([]string, error) {
+	return d.keywords[userID], nil
+}
+
+func (d *Dataset) Popular(_ context.Context, limit int) ([]model.Dishes, error) {
+	if limit > len(d.popular) {
+		limit = len(d.popular)
+	}
+	return d.popular[:limit], nil
+}
+
+// memStore ItemCF 近邻表的内存存储，评估时不写数据库
+type memStore struct {
+	snap *recommend.Snapshot
+}
+
+func (m *memStore) ActiveVersion(context.Context) (int64, error) {
+	if m.snap == nil {
+		return 0, nil
+	}
+	return m.snap.Version, nil
+}
+
+func (m *memStore) Load(context.Context) (*recommend.Snapshot, error) {
+	return m.snap, nil
+}
+
+func (m *memStore) Save(_ context.Context, snap *recommend.Snapshot, _ config.ItemCF) error {
+	snap.Version = time.Now().UnixMilli()
+	m.snap = snap
+	return nil
+}
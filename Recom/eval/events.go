@@ -0,0 +1,348 @@
+package eval
+
+import (
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/Basic/model"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 行为类型，曝光、点击、停留和不感兴趣为推荐结果上的反馈事件
+const (
+	EventLike       = "like"
+	EventRating     = "rating"
+	EventHistory    = "history"
+	EventSearch     = "search"
+	EventImpression = model.EventImpression
+	EventClick      = model.EventClick
+	EventDwell      = model.EventDwell
+	EventDismiss    = model.EventDismiss
+)
+
+// Event 一条带时间的用户行为。点赞和评分必须有 DishID，StoreID 可选（用于把浏览展开到店内菜品）；
+// 浏览只有 StoreID；搜索只有 Keyword；推荐反馈事件必须有 DishID，另带推荐请求 ID、位置和停留时长
+type Event struct {
+	Type      string    `json:"type"`
+	UserID    uint      `json:"user_id"`
+	DishID    uint      `json:"dish_id,omitempty"`
+	StoreID   uint      `json:"store_id,omitempty"`
+	Rating    uint      `json:"rating,omitempty"`
+	Keyword   string    `json:"keyword,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Position  int       `json:"position,omitempty"`
+	DwellMs   int       `json:"dwell_ms,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// csvHeader CSV 导出的列，time 为 RFC 3339 或 Unix 秒。
+// 推荐反馈事件的三列在最后，只有前 7 列的旧导出文件仍可读取
+var csvHeader = []string{"type", "user_id", "dish_id", "store_id", "rating", "keyword", "time", "request_id", "position", "dwell_ms"}
+
+// legacyColumns 旧导出文件的列数
+const legacyColumns = 7
+
+func (e Event) validate() error {
+	if e.UserID == 0 {
+		return errors.New("user_id is required")
+	}
+	if e.Time.IsZero() {
+		return errors.New("time is required")
+	}
+	switch e.Type {
+	case EventLike:
+		if e.DishID == 0 {
+			return errors.New("dish_id is required")
+		}
+	case EventRating:
+		if e.DishID == 0 || e.Rating == 0 {
+			return errors.New("dish_id and rating are required")
+		}
+	case EventHistory:
+		if e.StoreID == 0 {
+			return errors.New("store_id is required")
+		}
+	case EventSearch:
+		if strings.TrimSpace(e.Keyword) == "" {
+			return errors.New("keyword is required")
+		}
+	case EventImpression, EventClick, EventDismiss:
+		if e.DishID == 0 {
+			return errors.New("dish_id is required")
+		}
+	case EventDwell:
+		if e.DishID == 0 || e.DwellMs <= 0 {
+			return errors.New("dish_id and dwell_ms are required")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", e.Type)
+	}
+	return nil
+}
+
+// LoadDB 读取数据库中的点赞、评分、浏览、搜索记录和推荐反馈事件，按时间排序，没有时间的记录无法切分，跳过
+func LoadDB(ctx context.Context) ([]Event, error) {
+	sources := []struct {
+		typ   string
+		query func(context.Context) ([]dao.Activity, error)
+	}{
+		{EventLike, dao.LikeActivities},
+		{EventRating, dao.RatingActivities},
+		{EventHistory, dao.HistoryActivities},
+		{EventSearch, dao.SearchActivities},
+		{"", dao.EventActivities}, // 类型取自事件本身
+	}
+	var events []Event
+	skipped := 0
+	for _, src := range sources {
+		rows, err := src.query(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if r.CreatedAt.IsZero() {
+				skipped++
+				continue
+			}
+			typ := src.typ
+			if typ == "" {
+				typ = r.Type
+			}
+			events = append(events, Event{
+				Type:      typ,
+				UserID:    r.UserID,
+				DishID:    r.DishID,
+				StoreID:   r.StoreID,
+				Rating:    r.Rating,
+				Keyword:   r.Keyword,
+				RequestID: r.RequestID,
+				Position:  r.Position,
+				DwellMs:   r.DwellMs,
+				Time:      r.CreatedAt,
+			})
+		}
+	}
+	if skipped > 0 {
+		slog.WarnContext(ctx, "skipped interactions without created_at", "count", skipped)
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// ReadFile 按扩展名读取 .csv 或 .jsonl 导出文件，按时间排序
+func ReadFile(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		events, err = readCSV(file)
+	case ".jsonl":
+		events, err = readJSONL(file)
+	default:
+		return nil, fmt.Errorf("%s: unsupported file type %q, want .csv or .jsonl", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	sortEvents(events)
+	return events, nil
+}
+
+// WriteFile 按扩展名把行为写入 .csv 或 .jsonl 文件，格式与 ReadFile 一致
+func WriteFile(path string, events []Event) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".csv" && ext != ".jsonl" {
+		return fmt.Errorf("%s: unsupported file type %q, want .csv or .jsonl", path, ext)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if ext == ".csv" {
+		err = writeCSV(w, events)
+	} else {
+		err = writeJSONL(w, events)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func readCSV(r io.Reader) ([]Event, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if len(header) != len(csvHeader) && len(header) != legacyColumns {
+		return nil, fmt.Errorf("header must be %s", strings.Join(csvHeader, ","))
+	}
+	for i, name := range header {
+		if strings.TrimSpace(name) != csvHeader[i] {
+			return nil, fmt.Errorf("header must be %s", strings.Join(csvHeader, ","))
+		}
+	}
+	// 之后每行的列数须与表头一致
+	cr.FieldsPerRecord = len(header)
+	var events []Event
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		e, err := parseCSV(rec)
+		if err == nil {
+			err = e.validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+}
+
+func parseCSV(rec []string) (Event, error) {
+	e := Event{Type: strings.TrimSpace(rec[0]), Keyword: rec[5]}
+	ids := []*uint{&e.UserID, &e.DishID, &e.StoreID, &e.Rating}
+	for i, dst := range ids {
+		s := strings.TrimSpace(rec[i+1])
+		if s == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return e, fmt.Errorf("invalid %s %q", csvHeader[i+1], s)
+		}
+		*dst = uint(v)
+	}
+	t, err := parseTime(strings.TrimSpace(rec[6]))
+	if err != nil {
+		return e, err
+	}
+	e.Time = t
+	if len(rec) == legacyColumns {
+		return e, nil
+	}
+	e.RequestID = rec[7]
+	ints := []*int{&e.Position, &e.DwellMs}
+	for i, dst := range ints {
+		s := strings.TrimSpace(rec[i+8])
+		if s == "" {
+			continue
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return e, fmt.Errorf("invalid %s %q", csvHeader[i+8], s)
+		}
+		*dst = v
+	}
+	return e, nil
+}
+
+// parseTime 接受 RFC 3339 或 Unix 秒
+func parseTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, want RFC 3339 or Unix seconds", s)
+	}
+	return t, nil
+}
+
+func readJSONL(r io.Reader) ([]Event, error) {
+	var events []Event
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var e Event
+		err := json.Unmarshal(sc.Bytes(), &e)
+		if err == nil {
+			err = e.validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return events, sc.Err()
+}
+
+func writeCSV(w io.Writer, events []Event) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range events {
+		rec := []string{e.Type, uintField(e.UserID), uintField(e.DishID), uintField(e.StoreID), uintField(e.Rating), e.Keyword, e.Time.UTC().Format(time.RFC3339),
+			e.RequestID, uintField(uint(e.Position)), uintField(uint(e.DwellMs))}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// uintField 0 写为空，与读取时的缺省值一致
+func uintField(v uint) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func writeJSONL(w io.Writer, events []Event) error {
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TrainingEvents 去掉模型不使用的曝光和停留事件，切分训练集前调用，避免大量曝光改变切分时间
+func TrainingEvents(events []Event) []Event {
+	res := make([]Event, 0, len(events))
+	for _, e := range events {
+		if e.Type != EventImpression && e.Type != EventDwell {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// sortEvents 按时间稳定排序，同一时刻的行为保持原有顺序
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+}
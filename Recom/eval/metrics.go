@@ -0,0 +1,189 @@
+package eval
+
+import (
+	recommend "Food_recommendation/Recom/ItemCF"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Options 评估参数。K 为推荐列表长度；测试期内点赞或评分不低于 MinRating 的菜品视为用户喜欢
+type Options struct {
+	K         int
+	MinRating uint
+}
+
+// Metrics 一个模型的评估结果。Precision、Recall、NDCG 和 MAP 为 @K 的用户平均值；
+// Coverage 为所有用户前 K 个推荐覆盖的菜品占目录的比例；Novelty 为推荐菜品在训练集中
+// 流行度的平均自信息 −log₂ p（越大越冷门）
+type Metrics struct {
+	Model     string        `json:"model"`
+	Users     int           `json:"users"`
+	Precision float64       `json:"precision"`
+	Recall    float64       `json:"recall"`
+	NDCG      float64       `json:"ndcg"`
+	MAP       float64       `json:"map"`
+	Coverage  float64       `json:"coverage"`
+	Novelty   float64       `json:"novelty"`
+	TrainTime time.Duration `json:"train_time"`
+}
+
+// Relevant 返回每个用户在测试期喜欢的菜品，训练期已点赞或评分过的菜品不计入
+func Relevant(train, test []Event, minRating uint) map[uint]map[uint]bool {
+	seen := make(map[[2]uint]bool)
+	for _, e := range train {
+		if e.Type == EventLike || e.Type == EventRating {
+			seen[[2]uint{e.UserID, e.DishID}] = true
+		}
+	}
+	res := make(map[uint]map[uint]bool)
+	for _, e := range test {
+		positive := e.Type == EventLike || (e.Type == EventRating && e.Rating >= minRating)
+		if !positive || seen[[2]uint{e.UserID, e.DishID}] {
+			continue
+		}
+		if res[e.UserID] == nil {
+			res[e.UserID] = make(map[uint]bool)
+		}
+		res[e.UserID][e.DishID] = true
+	}
+	return res
+}
+
+// Run 用训练集训练 variant 对应的模型，再为 relevant 中的每个用户生成推荐并计算指标
+func Run(ctx context.Context, v Variant, data *Dataset, relevant map[uint]map[uint]bool, opts Options) (Metrics, error) {
+	cfg := v.Config
+	if cfg.MaxResults < opts.K {
+		cfg.MaxResults = opts.K
+	}
+	// ALS 模型写入临时目录，不覆盖线上的模型文件
+	dir, err := os.MkdirTemp("", "recom-eval-*")
+	if err != nil {
+		return Metrics{}, err
+	}
+	defer os.RemoveAll(dir)
+	cfg.ALS.ModelPath = filepath.Join(dir, "als.gob")
+
+	model, err := recommend.NewWithStore(cfg, nil, data, &memStore{})
+	if err != nil {
+		return Metrics{}, err
+	}
+	start := time.Now()
+	if _, err := model.Update(ctx, true); err != nil {
+		return Metrics{}, fmt.Errorf("train %s: %w", v.Name, err)
+	}
+	m := Metrics{Model: v.Name, TrainTime: time.Since(start).Round(time.Millisecond)}
+
+	users := make([]uint, 0, len(relevant))
+	for u := range relevant {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i] < users[j] })
+	lists := make([][]uint, len(users))
+	errs := make([]error, len(users))
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(users); i += workers {
+				res, err := model.Recommend(ctx, users[i])
+				if err != nil {
+					errs[i] = fmt.Errorf("recommend for user %d: %w", users[i], err)
+					continue
+				}
+				for k := 0; k < len(res) && k < opts.K; k++ {
+					lists[i] = append(lists[i], res[k].Dish.ID)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return Metrics{}, err
+		}
+	}
+
+	pop := popularity(data)
+	recommended := make(map[uint]bool)
+	var items int
+	for i, u := range users {
+		rel := relevant[u]
+		precision, recall, ndcg, ap := score(lists[i], rel, opts.K)
+		m.Precision += precision
+		m.Recall += recall
+		m.NDCG += ndcg
+		m.MAP += ap
+		for _, id := range lists[i] {
+			recommended[id] = true
+			m.Novelty += -math.Log2(pop(id))
+			items++
+		}
+	}
+	m.Users = len(users)
+	if m.Users > 0 {
+		n := float64(m.Users)
+		m.Precision /= n
+		m.Recall /= n
+		m.NDCG /= n
+		m.MAP /= n
+	}
+	if items > 0 {
+		m.Novelty /= float64(items)
+	}
+	if len(data.catalog) > 0 {
+		m.Coverage = float64(len(recommended)) / float64(len(data.catalog))
+	}
+	return m, nil
+}
+
+// score 计算单个用户的 precision@K、recall@K、NDCG@K 和 AP@K（相关性为 0/1），
+// 只看 list 的前 k 个；rel 为空时各项为 0
+func score(list []uint, rel map[uint]bool, k int) (precision, recall, ndcg, ap float64) {
+	if len(rel) == 0 || k <= 0 {
+		return 0, 0, 0, 0
+	}
+	if len(list) > k {
+		list = list[:k]
+	}
+	var hits int
+	var dcg, sumPrec float64
+	for i, id := range list {
+		if rel[id] {
+			hits++
+			dcg += 1 / math.Log2(float64(i+2))
+			sumPrec += float64(hits) / float64(i+1)
+		}
+	}
+	var idcg float64
+	for i := 0; i < len(rel) && i < k; i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	precision = float64(hits) / float64(k)
+	recall = float64(hits) / float64(len(rel))
+	ndcg = dcg / idcg
+	ap = sumPrec / float64(min(len(rel), k))
+	return precision, recall, ndcg, ap
+}
+
+// popularity 返回菜品在训练集中被点赞或评分的用户比例，加一平滑，训练集未出现的菜品也有非零流行度
+func popularity(data *Dataset) func(id uint) float64 {
+	count := make(map[uint]int)
+	for _, in := range data.interactions {
+		if in.Liked || in.Rating > 0 {
+			count[in.DishID]++
+		}
+	}
+	users := float64(len(data.byUser))
+	return func(id uint) float64 {
+		return (float64(count[id]) + 1) / (users + 1)
+	}
+}
@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"Food_recommendation/Basic/model"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func set(ids ...uint) map[uint]bool {
+	res := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name                             string
+		list                             []uint
+		rel                              map[uint]bool
+		k                                int
+		precision, recall, ndcg, avgPrec float64
+	}{
+		{
+			// 命中第 1、3 位：DCG = 1 + 1/log₂4 = 1.5，IDCG = 1 + 1/log₂3 + 1/log₂4；AP = (1/1 + 2/3) / 3
+			name: "hits at 1 and 3", list: []uint{1, 2, 3, 4, 5}, rel: set(1, 3, 6), k: 5,
+			precision: 0.4, recall: 2.0 / 3, ndcg: 0.7039180890, avgPrec: 0.5555555556,
+		},
+		{
+			// 相关菜品少于 k：IDCG 只累加 1 位，AP 除以 len(rel)
+			name: "fewer relevant than k", list: []uint{7, 8, 9, 10}, rel: set(8), k: 4,
+			precision: 0.25, recall: 1, ndcg: 0.6309297536, avgPrec: 0.5,
+		},
+		{
+			name: "perfect", list: []uint{1, 2}, rel: set(2, 1), k: 2,
+			precision: 1, recall: 1, ndcg: 1, avgPrec: 1,
+		},
+		{
+			// 推荐列表短于 k：precision 仍除以 k，IDCG = 1 + 1/log₂3
+			name: "list shorter than k", list: []uint{1}, rel: set(1, 2), k: 3,
+			precision: 1.0 / 3, recall: 0.5, ndcg: 0.6131471928, avgPrec: 0.5,
+		},
+		{
+			name: "list longer than k", list: []uint{9, 1}, rel: set(1), k: 1,
+		},
+		{
+			name: "no hits", list: []uint{4, 5, 6}, rel: set(1, 2), k: 3,
+		},
+		{
+			name: "empty list", list: nil, rel: set(1), k: 3,
+		},
+		{
+			name: "empty relevant", list: []uint{1, 2}, rel: set(), k: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			precision, recall, ndcg, ap := score(tt.list, tt.rel, tt.k)
+			got := []float64{precision, recall, ndcg, ap}
+			want := []float64{tt.precision, tt.recall, tt.ndcg, tt.avgPrec}
+			for i, name := range []string{"precision", "recall", "ndcg", "ap"} {
+				if math.IsNaN(got[i]) || math.Abs(got[i]-want[i]) > 1e-9 {
+					t.Errorf("%s = %v, want %v", name, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRelevant(t *testing.T) {
+	at := time.Unix(0, 0)
+	train := []Event{
+		{Type: EventLike, UserID: 1, DishID: 10, Time: at},
+		{Type: EventRating, UserID: 3, DishID: 13, Rating: 1, Time: at},
+		{Type: EventHistory, UserID: 4, StoreID: 1, Time: at},
+	}
+	test := []Event{
+		{Type: EventLike, UserID: 1, DishID: 10, Time: at},              // 训练期已点赞
+		{Type: EventRating, UserID: 1, DishID: 11, Rating: 3, Time: at}, // 评分低于 minRating
+		{Type: EventRating, UserID: 1, DishID: 12, Rating: 4, Time: at},
+		{Type: EventLike, UserID: 2, DishID: 10, Time: at},
+		{Type: EventClick, UserID: 2, DishID: 11, Time: at},
+		{Type: EventLike, UserID: 3, DishID: 13, Time: at}, // 训练期评过分，即使分数低也不计入
+		{Type: EventLike, UserID: 4, DishID: 14, Time: at}, // 训练期只浏览过店铺
+	}
+	want := map[uint]map[uint]bool{1: set(12), 2: set(10), 4: set(14)}
+	if got := Relevant(train, test, 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("Relevant = %v, want %v", got, want)
+	}
+	if got := Relevant(train, nil, 4); len(got) != 0 {
+		t.Errorf("Relevant without test events = %v, want empty", got)
+	}
+}
+
+func TestPopularity(t *testing.T) {
+	catalog := []model.Dishes{{ID: 10, StoreID: 1}, {ID: 11, StoreID: 1}, {ID: 12, StoreID: 1}}
+	at := time.Unix(0, 0)
+	data := NewDataset([]Event{
+		{Type: EventLike, UserID: 1, DishID: 10, Time: at},
+		{Type: EventRating, UserID: 2, DishID: 10, Rating: 5, Time: at},
+		{Type: EventLike, UserID: 3, DishID: 11, Time: at},
+	}, catalog)
+	pop := popularity(data)
+	// 3 个用户，加一平滑：(count + 1) / (3 + 1)
+	for id, want := range map[uint]float64{10: 0.75, 11: 0.5, 12: 0.25, 99: 0.25} {
+		if got := pop(id); math.Abs(got-want) > 1e-9 {
+			t.Errorf("popularity(%d) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteReport 以表格输出评估结果，每个模型一列；两个模型时增加一列差值（第二个减第一个）
+func WriteReport(w io.Writer, results []Metrics, k int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"metric"}
+	for _, m := range results {
+		header = append(header, m.Model)
+	}
+	if len(results) == 2 {
+		header = append(header, "delta")
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+
+	rows := []struct {
+		name  string
+		value func(Metrics) float64
+	}{
+		{fmt.Sprintf("precision@%d", k), func(m Metrics) float64 { return m.Precision }},
+		{fmt.Sprintf("recall@%d", k), func(m Metrics) float64 { return m.Recall }},
+		{fmt.Sprintf("ndcg@%d", k), func(m Metrics) float64 { return m.NDCG }},
+		{fmt.Sprintf("map@%d", k), func(m Metrics) float64 { return m.MAP }},
+		{"coverage", func(m Metrics) float64 { return m.Coverage }},
+		{"novelty", func(m Metrics) float64 { return m.Novelty }},
+	}
+	for _, r := range rows {
+		line := []string{r.name}
+		for _, m := range results {
+			line = append(line, fmt.Sprintf("%.4f", r.value(m)))
+		}
+		if len(results) == 2 {
+			line = append(line, fmt.Sprintf("%+.4f", r.value(results[1])-r.value(results[0])))
+		}
+		fmt.Fprintln(tw, strings.Join(line, "\t")+"\t")
+	}
+
+	users := []string{"users"}
+	train := []string{"train time"}
+	for _, m := range results {
+		users = append(users, fmt.Sprint(m.Users))
+		train = append(train, m.TrainTime.String())
+	}
+	fmt.Fprintln(tw, strings.Join(users, "\t")+"\t")
+	fmt.Fprintln(tw, strings.Join(train, "\t")+"\t")
+	return tw.Flush()
+}
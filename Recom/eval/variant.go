@@ -0,0 +1,82 @@
+package eval
+
+import (
+	recommend "Food_recommendation/Recom/ItemCF"
+	"Food_recommendation/config"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// Variant 一个待评估的模型配置，Name 为命令行上的写法
+type Variant struct {
+	Name   string
+	Config config.Recommend
+}
+
+// ParseVariant 解析 model[:key=value,...]。model 为 recommend.model 的取值，key 为该模型配置段
+// （item_cf、als、content 或 blend）中的 yaml 字段名，value 按 yaml 解析，如
+// itemcf:similarity=adjusted_cosine,neighbors=50 或 content:price_bands=[30,60]。
+// 未覆盖的字段取 base 中的值，覆盖后的配置需通过校验
+func ParseVariant(spec string, base config.Config) (Variant, error) {
+	name, overrides, _ := strings.Cut(spec, ":")
+	cfg := base
+	cfg.Recommend.Model = name
+	// 切片字段整体覆盖，不与 base 共享底层数组
+	cfg.Recommend.Content.PriceBands = append([]float64(nil), base.Recommend.Content.PriceBands...)
+	cfg.Recommend.Blend.Sources = append([]config.BlendSource(nil), base.Recommend.Blend.Sources...)
+
+	var section interface{}
+	switch name {
+	case recommend.ModelItemCF:
+		section = &cfg.Recommend.ItemCF
+	case recommend.ModelALS:
+		section = &cfg.Recommend.ALS
+	case recommend.ModelContent, recommend.ModelSearch:
+		section = &cfg.Recommend.Content
+	case recommend.ModelBlend:
+		section = &cfg.Recommend.Blend
+	}
+	if overrides != "" {
+		if section == nil {
+			return Variant{}, fmt.Errorf("variant %q: model %q has no settings", spec, name)
+		}
+		var doc strings.Builder
+		for _, kv := range splitOverrides(overrides) {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return Variant{}, fmt.Errorf("variant %q: %q is not key=value", spec, kv)
+			}
+			fmt.Fprintf(&doc, "%s: %s\n", strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+		dec := yaml.NewDecoder(strings.NewReader(doc.String()))
+		dec.KnownFields(true)
+		if err := dec.Decode(section); err != nil {
+			return Variant{}, fmt.Errorf("variant %q: %w", spec, err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return Variant{}, fmt.Errorf("variant %q: %w", spec, err)
+	}
+	return Variant{Name: spec, Config: cfg.Recommend}, nil
+}
+
+// splitOverrides 按逗号切分，方括号内的逗号属于列表取值
+func splitOverrides(s string) []string {
+	var res []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				res = append(res, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(res, s[start:])
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	recommend "Food_recommendation/Recom/ItemCF"
+	gen "Food_recommendation/Recom/proto/gen"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"Food_recommendation/Basic/dao"
+	"Food_recommendation/utils"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	healthInterval = 10 * time.Second
+	pingTimeout    = 2 * time.Second
+)
+
+// draining 收到退出信号后置位，就绪探针随即失败
+var draining atomic.Bool
+
+// watchHealth 定期检查数据库连接，把结果同步到 gRPC 健康服务，ctx 取消后退出
+func watchHealth(ctx context.Context, hs *health.Server) {
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if err := pingDB(ctx); err != nil {
+			slog.Warn("health check: database unavailable", "err", err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		hs.SetServingStatus("", status)
+		hs.SetServingStatus(gen.RecommendService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func pingDB(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	return dao.Ping(ctx)
+}
+
+// healthHandler 提供 HTTP 存活与就绪探针和 Prometheus 指标。模型未加载时请求会由 API 端兜底，
+// 只在就绪检查中展示，不影响就绪状态
+func healthHandler(model recommend.Recommender) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", utils.MetricsHandler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		checks := map[string]string{"database": "ok", "model": "not loaded"}
+		if v := model.Version(); v > 0 {
+			checks["model"] = "version " + strconv.FormatInt(v, 10)
+		}
+		ready := true
+		if draining.Load() {
+			ready = false
+			checks["server"] = "shutting down"
+		}
+		if err := pingDB(r.Context()); err != nil {
+			ready = false
+			slog.WarnContext(r.Context(), "readiness check failed", "check", "database", "err", err)
+			checks["database"] = "unavailable"
+		}
+		if !ready {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "unavailable", "checks": checks})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ready", "checks": checks})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	recommend "Food_recommendation/Recom/ItemCF"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+const precomputeUsage = `usage: recom [-config path] precompute [flags]
+
+Recompute the model selected by recommend.model from all likes, ratings and
+histories and make it the active version:
+  itemcf  dish neighbors are written to the dish_similarity table
+  als     the trained model is written to recommend.als.model_path
+  content the feature index only lives in memory; this checks that it builds
+  search  same as content
+  popular the ranking only lives in memory; this checks that it loads
+  blend   every model listed in recommend.blend.sources
+Running recommend instances pick it up within recommend.precompute.reload.
+
+flags:
+  -dry-run compute and print statistics without saving
+`
+
+// runPrecompute 执行 precompute 子命令，返回进程退出码
+func runPrecompute(model recommend.Recommender, args []string) int {
+	fs := flag.NewFlagSet("precompute", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, precomputeUsage) }
+	dryRun := fs.Bool("dry-run", false, "compute without saving")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	start := time.Now()
+	stats, err := model.Update(context.Background(), !*dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if *dryRun {
+		fmt.Printf("%s computed in %s (not saved)\n", stats, elapsed)
+	} else {
+		fmt.Printf("version %d: %s saved in %s\n", model.Version(), stats, elapsed)
+	}
+	return 0
+}
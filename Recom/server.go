@@ -3,77 +3,215 @@ package main
 import (
 	recommend "Food_recommendation/Recom/ItemCF"
 	gen "Food_recommendation/Recom/proto/gen"
+	"Food_recommendation/config"
+	"Food_recommendation/utils"
 	"context"
-	"fmt"
-	"log"
+	"errors"
+	"flag"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"Food_recommendation/Basic/dao"
-	"Food_recommendation/Basic/model"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 )
 
 // RecommendServer 实现 RecommendService 接口
 type RecommendServer struct {
 	gen.UnimplementedRecommendServiceServer
+	model       string
+	experiments []config.Experiment
+	diversity   config.Diversity
 }
 
 // DishRecommend 实现菜品推荐方法
 func (s *RecommendServer) DishRecommend(ctx context.Context, req *gen.DishRecommendRequest) (*gen.DishRecommendResponse, error) {
-	// 调用 ItemCF 函数获取推荐菜品
-	recommendedDishes, err := recommend.ItemCF(ctx, uint(req.UserID))
+	// 获取按得分排序的推荐菜品
+	recommendedDishes, err := recommend.Recommend(ctx, uint(req.UserID))
+	if errors.Is(err, recommend.ErrNotReady) {
+		// 不计入客户端熔断，API 端直接返回热门菜品
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(req.From, req.To)
+	// 不感兴趣的菜品、店铺和标签在模型打分之后过滤，标记后立即生效
+	if recommendedDishes, err = filterNotInterested(ctx, uint(req.UserID), recommendedDishes); err != nil {
+		return nil, err
+	}
+	// 过滤之后、分页之前做多样性重排，同一用户各页的顺序一致
+	recommendedDishes = recommend.Diversify(recommendedDishes, s.diversity)
+	slog.DebugContext(ctx, "dish recommend", "user_id", req.UserID, "from", req.From, "to", req.To, "candidates", len(recommendedDishes))
 	// 截取需要的推荐数量
-	if len(recommendedDishes) > int(req.To) {
+	if req.From >= req.To || int(req.From) >= len(recommendedDishes) {
+		recommendedDishes = nil
+	} else if len(recommendedDishes) > int(req.To) {
 		recommendedDishes = recommendedDishes[req.From:req.To]
-	} else if len(recommendedDishes) > int(req.From) {
-		recommendedDishes = recommendedDishes[req.From:]
 	} else {
-		recommendedDishes = []model.Dishes{}
+		recommendedDishes = recommendedDishes[req.From:]
 	}
 
-	response := &gen.DishRecommendResponse{}
-	for _, dish := range recommendedDishes {
-		// 获取店铺名称
-		store, err := dao.SearchStore(ctx, dish.StoreID)
-		if err != nil {
-			return nil, err
+	response := &gen.DishRecommendResponse{Model: s.model}
+	// 与推荐模型选择策略使用同一分组，API 端据此记录曝光
+	if a, ok := utils.AssignExperiment(s.experiments, uint(req.UserID)); ok {
+		response.Experiment, response.Variant = a.Experiment, a.Variant
+		if a.Model != "" {
+			response.Model = a.Model
 		}
-
+	}
+	for _, item := range recommendedDishes {
+		dish := item.Dish
 		merchant := &gen.ShowMerchant{
 			Img:        dish.ImageURL,
 			DishesName: dish.Name,
 			DishesID:   uint32(dish.ID),
-			StoreName:  store.Name,
+			StoreName:  dish.Store.Name,
 			Likenum:    uint32(dish.LikeNum),
 			Rating:     strconv.FormatFloat(dish.AvgRating, 'f', 1, 64),
 			Link:       "store/" + strconv.FormatUint(uint64(dish.StoreID), 10),
 		}
+		for _, r := range item.Reasons {
+			merchant.Reasons = append(merchant.Reasons, &gen.Reason{Source: r.Source, Text: r.Text})
+		}
 		response.Recommendations = append(response.Recommendations, merchant)
 	}
 
 	return response, nil
 }
+
+// filterNotInterested 去掉用户标记为不感兴趣的菜品，返回新的切片，不修改模型返回的结果
+func filterNotInterested(ctx context.Context, userID uint, dishes []recommend.ScoredDish) ([]recommend.ScoredDish, error) {
+	ids := make([]uint, 0, len(dishes))
+	for _, d := range dishes {
+		ids = append(ids, d.Dish.ID)
+	}
+	visible, err := dao.VisibleDishIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]recommend.ScoredDish, 0, len(dishes))
+	for _, d := range dishes {
+		if visible[d.Dish.ID] {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
 func main() {
-	// 初始化数据库
-	dao.InitDB()
+	configPath := flag.String("config", "", "path to config file (default $FOOD_CONFIG or config.yaml)")
+	flag.Parse()
+	cfg := config.MustLoad(*configPath)
+	utils.InitLogger(cfg.Log)
+	shutdownTracing, err := utils.InitTracing(context.Background(), cfg.Tracing, utils.ServiceRecommend)
+	if err != nil {
+		fatal("init tracing failed", err)
+	}
+
+	if flag.Arg(0) == "eval" {
+		utils.ExitAfterFlush(runEval(cfg, flag.Args()[1:]), shutdownTracing)
+	}
+	if err := openDB(cfg.Database); err != nil {
+		fatal("database schema not ready", err)
+	}
+	model, err := recommend.New(cfg.Recommend, cfg.Experiments, recommend.DBDataset{})
+	if err != nil {
+		fatal("init recommend model failed", err)
+	}
+	if flag.Arg(0) == "precompute" {
+		utils.ExitAfterFlush(runPrecompute(model, flag.Args()[1:]), shutdownTracing)
+	}
+	recommend.Init(model)
 
 	// 创建 gRPC 服务器
-	lis, err := net.Listen("tcp", ":8088")
+	lis, err := net.Listen("tcp", cfg.Recommend.Addr)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		fatal("failed to listen", err)
 	}
-	s := grpc.NewServer()
+	s := grpc.NewServer(
+		// 允许 API 端的长连接按 recommend.keepalive（不小于 10s）探活
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 10 * time.Second, PermitWithoutStream: true}),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(utils.RequestIDServerInterceptor(), utils.MetricsServerInterceptor()),
+	)
+
+	// 注册服务和标准健康检查服务
+	gen.RegisterRecommendServiceServer(s, &RecommendServer{model: cfg.Recommend.Model, experiments: cfg.Experiments, diversity: cfg.Recommend.Diversity})
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go watchHealth(sigCtx, hs)
+	// 租约有效期为两个重算周期，持有者按周期续期，宕机后其他实例最多等待两个周期接管
+	go recommend.Run(sigCtx, model, cfg.Recommend.Precompute, recommend.NewDBLease(2*cfg.Recommend.Precompute.Interval))
 
-	// 注册服务
-	gen.RegisterRecommendServiceServer(s, &RecommendServer{})
+	probe := &http.Server{Addr: cfg.Recommend.HealthAddr, Handler: healthHandler(model)}
+	go func() {
+		if err := probe.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("health listen failed", err)
+		}
+	}()
+	go func() {
+		slog.Info("recommend server started", "addr", cfg.Recommend.Addr, "health_addr", cfg.Recommend.HealthAddr, "model", cfg.Recommend.Model)
+		if err := s.Serve(lis); err != nil {
+			fatal("failed to serve", err)
+		}
+	}()
 
-	log.Println("Starting gRPC ItemCF on port 50051...")
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	<-sigCtx.Done()
+	stop()
+	// 健康状态先置为 NOT_SERVING，等待 drain_delay 让客户端切走，再等待进行中的调用完成，超时后强制关闭
+	slog.Info("shutting down, draining in-flight calls", "drain_delay", cfg.Recommend.DrainDelay.String(), "timeout", cfg.Recommend.ShutdownTimeout.String())
+	draining.Store(true)
+	hs.Shutdown()
+	time.Sleep(cfg.Recommend.DrainDelay)
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(cfg.Recommend.ShutdownTimeout):
+		slog.Warn("graceful stop timed out, forcing close")
+		s.Stop()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := probe.Shutdown(ctx); err != nil {
+		slog.Error("health server shutdown failed", "err", err)
+	}
+	if err := dao.Close(); err != nil {
+		slog.Error("close database failed", "err", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("flush traces failed", "err", err)
 	}
+	slog.Info("server exited")
+}
+
+// openDB 初始化数据库，迁移由 Basic 服务负责，这里只检查结构是否就绪
+func openDB(db config.Database) error {
+	dao.InitDB(db)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return dao.EnsureSchema(ctx, false)
+}
+
+// fatal 记录错误并退出进程
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
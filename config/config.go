@@ -0,0 +1,801 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPath 默认配置文件路径，可通过 FOOD_CONFIG 环境变量覆盖
+const DefaultPath = "config.yaml"
+
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	JWT       JWT       `yaml:"jwt"`
+	Crypto    Crypto    `yaml:"crypto"`
+	Recommend Recommend `yaml:"recommend"`
+	// Experiments 推荐策略的线上 A/B 实验，API 服务和推荐服务必须使用相同的配置
+	Experiments []Experiment `yaml:"experiments"`
+	Notifier    Notifier     `yaml:"notifier"`
+	Verify      Verify       `yaml:"verify"`
+	RateLimit   RateLimit    `yaml:"rate_limit"`
+	Lockout     Lockout      `yaml:"lockout"`
+	I18n        I18n         `yaml:"i18n"`
+	Log         Log          `yaml:"log"`
+	Tracing     Tracing      `yaml:"tracing"`
+}
+
+// Server Gin API 服务配置，MetricsAddr 为 Prometheus 指标（/metrics）的内部监听地址，为空时不暴露
+type Server struct {
+	Addr            string        `yaml:"addr"`
+	MetricsAddr     string        `yaml:"metrics_addr"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // 收到退出信号后等待进行中请求完成的最长时间
+	DrainDelay      time.Duration `yaml:"drain_delay"`      // 就绪探针失败后继续接收请求的时间，留给负载均衡摘除实例
+}
+
+// Database 数据库连接与连接池配置
+type Database struct {
+	Driver          string        `yaml:"driver"` // mysql 或 sqlite
+	DSN             string        `yaml:"dsn"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	AutoMigrate     bool          `yaml:"auto_migrate"` // 启动时执行未应用的迁移，关闭后需先运行 migrate 子命令
+}
+
+// JWT 令牌签名配置
+type JWT struct {
+	Secret     string        `yaml:"secret"`
+	Issuer     string        `yaml:"issuer"`
+	AccessTTL  time.Duration `yaml:"access_ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+}
+
+// Crypto 加密相关配置
+type Crypto struct {
+	AESKey string `yaml:"aes_key"`
+}
+
+// Recommend 推荐服务配置，Addr 为 gRPC 服务监听地址，Target 为 API 端拨号地址，
+// HealthAddr 为推荐服务 HTTP 探针（/healthz、/readyz）和指标（/metrics）监听地址
+type Recommend struct {
+	Addr            string        `yaml:"addr"`
+	Target          string        `yaml:"target"`
+	Timeout         time.Duration `yaml:"timeout"`
+	HealthAddr      string        `yaml:"health_addr"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	DrainDelay      time.Duration `yaml:"drain_delay"` // 健康检查置为 NOT_SERVING 后继续处理调用的时间
+	Keepalive       time.Duration `yaml:"keepalive"`   // 客户端空闲连接的探活间隔
+	Retry           Retry         `yaml:"retry"`
+	Breaker         Breaker       `yaml:"breaker"`
+	Fallback        Fallback      `yaml:"fallback"`
+	Model           string        `yaml:"model"`       // 推荐模型：itemcf、als、content、search、popular 或 blend
+	MaxResults      int           `yaml:"max_results"` // 每个用户最多返回的推荐数
+	ItemCF          ItemCF        `yaml:"item_cf"`
+	ALS             ALS           `yaml:"als"`
+	Content         Content       `yaml:"content"`
+	Blend           Blend         `yaml:"blend"`
+	Diversity       Diversity     `yaml:"diversity"`
+	Precompute      Precompute    `yaml:"precompute"`
+}
+
+// Blend 混合推荐：并行调用 Sources 中的模型，各自的得分按 Normalize 归一化到 [0, 1]
+// （max 除以最高分，minmax 按最低分和最高分线性缩放，rank 按排名）后乘 Weight 相加
+type Blend struct {
+	Normalize string        `yaml:"normalize"`
+	Sources   []BlendSource `yaml:"sources"`
+}
+
+func (b Blend) validate() []string {
+	var errs []string
+	switch b.Normalize {
+	case "max", "minmax", "rank":
+	default:
+		errs = append(errs, "recommend.blend.normalize must be max, minmax or rank")
+	}
+	if len(b.Sources) == 0 {
+		errs = append(errs, "recommend.blend.sources must not be empty")
+	}
+	seen := make(map[string]bool, len(b.Sources))
+	for _, src := range b.Sources {
+		switch src.Model {
+		case "itemcf", "als", "content", "search", "popular":
+		default:
+			errs = append(errs, fmt.Sprintf("recommend.blend.sources: unknown model %q", src.Model))
+		}
+		if seen[src.Model] {
+			errs = append(errs, fmt.Sprintf("recommend.blend.sources: duplicate model %q", src.Model))
+		}
+		seen[src.Model] = true
+		if src.Weight <= 0 {
+			errs = append(errs, fmt.Sprintf("recommend.blend.sources: weight of %q must be positive", src.Model))
+		}
+	}
+	return errs
+}
+
+// Experiment 一个推荐策略实验。按用户 ID 的哈希取 Traffic% 的用户参与，参与的用户再按 Weight
+// 比例分到各 Variant；同一用户在同一实验中的分组固定。多个实验依次判断，用户进入第一个命中的实验
+type Experiment struct {
+	Name     string              `yaml:"name"`
+	Enabled  bool                `yaml:"enabled"`
+	Traffic  int                 `yaml:"traffic"` // 参与实验的用户百分比，0-100
+	Variants []ExperimentVariant `yaml:"variants"`
+}
+
+// ExperimentVariant 实验分组及其推荐策略，未设置的项使用 recommend 下的配置：
+// Model 为推荐模型，Blend 为 model 为 blend 时的混合配置，MaxResults 为每个用户最多返回的推荐数
+type ExperimentVariant struct {
+	Name       string `yaml:"name"`
+	Weight     int    `yaml:"weight"`
+	Model      string `yaml:"model"`
+	Blend      *Blend `yaml:"blend"`
+	MaxResults int    `yaml:"max_results"`
+}
+
+func (e Experiment) validate() []string {
+	var errs []string
+	if e.Name == "" {
+		errs = append(errs, "experiments: name is required")
+	}
+	if e.Traffic < 0 || e.Traffic > 100 {
+		errs = append(errs, fmt.Sprintf("experiments.%s.traffic must be between 0 and 100", e.Name))
+	}
+	if len(e.Variants) == 0 {
+		errs = append(errs, fmt.Sprintf("experiments.%s.variants must not be empty", e.Name))
+	}
+	seen := make(map[string]bool, len(e.Variants))
+	for _, v := range e.Variants {
+		prefix := fmt.Sprintf("experiments.%s.variants.%s", e.Name, v.Name)
+		if v.Name == "" || seen[v.Name] {
+			errs = append(errs, fmt.Sprintf("experiments.%s: variant names must be unique and not empty", e.Name))
+		}
+		seen[v.Name] = true
+		if v.Weight <= 0 {
+			errs = append(errs, prefix+".weight must be positive")
+		}
+		switch v.Model {
+		case "", "itemcf", "als", "content", "search", "popular", "blend":
+		default:
+			errs = append(errs, prefix+".model must be itemcf, als, content, search, popular or blend")
+		}
+		if v.Blend != nil {
+			if v.Model != "blend" {
+				errs = append(errs, prefix+".blend requires model blend")
+			}
+			errs = append(errs, v.Blend.validate()...)
+		}
+		if v.MaxResults < 0 {
+			errs = append(errs, prefix+".max_results must not be negative")
+		}
+	}
+	return errs
+}
+
+// BlendSource 混合推荐的一路候选，Model 不能为 blend
+type BlendSource struct {
+	Model  string  `yaml:"model"`
+	Weight float64 `yaml:"weight"`
+}
+
+// ItemCF 菜品协同过滤参数。Similarity 为 cosine 或 adjusted_cosine（先减去用户平均分，
+// 只点赞未评分的用户不提供信号）；Neighbors 为每个菜品保留的最相似邻居数；
+// LikeWeight 为一次点赞折算的分值，评分按 Num 计；推荐结果中点击 n 次计 ClickWeight × log2(1+n) 分，
+// 标记不感兴趣扣 DismissWeight 分，两者为 0 时不使用推荐反馈事件
+type ItemCF struct {
+	Similarity    string  `yaml:"similarity"`
+	Neighbors     int     `yaml:"neighbors"`
+	LikeWeight    float64 `yaml:"like_weight"`
+	ClickWeight   float64 `yaml:"click_weight"`
+	DismissWeight float64 `yaml:"dismiss_weight"`
+	// Deprecated: 已移到 recommend.max_results，仅在后者未配置时沿用
+	MaxResults int `yaml:"max_results"`
+}
+
+// ALS 隐式反馈矩阵分解参数。用户对菜品的分值 r 为点赞（LikeWeight）、评分（Num）和
+// 浏览所在店铺的次数 × VisitWeight 之和，置信度 c = 1 + Alpha × r；
+// 训练结果写入 ModelPath，多实例部署时各实例需能读到同一文件
+type ALS struct {
+	Factors        int     `yaml:"factors"`
+	Regularization float64 `yaml:"regularization"`
+	Iterations     int     `yaml:"iterations"`
+	Alpha          float64 `yaml:"alpha"`
+	LikeWeight     float64 `yaml:"like_weight"`
+	VisitWeight    float64 `yaml:"visit_weight"`
+	ModelPath      string  `yaml:"model_path"`
+}
+
+// Retry 推荐调用失败（UNAVAILABLE）时的重试策略，MaxAttempts 含首次调用
+type Retry struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+}
+
+// Breaker 熔断配置，连续失败 Threshold 次后熔断，Cooldown 后放行一次试探调用
+type Breaker struct {
+	Threshold int           `yaml:"threshold"`
+	Cooldown  time.Duration `yaml:"cooldown"`
+}
+
+// Fallback 推荐服务不可用时的热门菜品兜底列表，Size 为列表长度，TTL 为本地缓存时间
+type Fallback struct {
+	Size int           `yaml:"size"`
+	TTL  time.Duration `yaml:"ttl"`
+}
+
+// Content 基于内容的推荐参数。菜品特征由标签（TF-IDF 加权，乘 TagWeight）、价格区间（PriceWeight）
+// 和店铺（StoreWeight）组成，用户画像为其点赞、评分和浏览过的菜品特征按分值加权之和；
+// 菜品名称、描述或标签命中用户最近搜索的关键词时按命中比例加 KeywordWeight 分。
+// PriceBands 为价格区间的上界（元），LikeWeight、VisitWeight 的含义与 ALS 相同
+type Content struct {
+	TagWeight     float64   `yaml:"tag_weight"`
+	PriceWeight   float64   `yaml:"price_weight"`
+	StoreWeight   float64   `yaml:"store_weight"`
+	KeywordWeight float64   `yaml:"keyword_weight"`
+	PriceBands    []float64 `yaml:"price_bands"`
+	LikeWeight    float64   `yaml:"like_weight"`
+	VisitWeight   float64   `yaml:"visit_weight"`
+}
+
+// Diversity 推荐结果的多样性重排，在分页之前执行。按 MMR 逐个位置选取 Lambda × 相关度 −
+// (1 − Lambda) × 与已选菜品的最大相似度 最高的菜品，相关度为得分除以最高分，两个菜品同店铺时相似度加
+// StoreWeight，标签的 Jaccard 系数乘 TagWeight 累加。每 Window 个位置中同一店铺最多 MaxPerStore 个、
+// 同一标签最多 MaxPerTag 个（0 不限制），其余菜品都超出上限时才放宽。
+// 每 SerendipitySlot 个位置的最后一个优先给点赞数不超过 LongTailLikes 的长尾菜品，0 表示不留位置
+type Diversity struct {
+	Enabled         bool    `yaml:"enabled"`
+	Lambda          float64 `yaml:"lambda"`
+	StoreWeight     float64 `yaml:"store_weight"`
+	TagWeight       float64 `yaml:"tag_weight"`
+	Window          int     `yaml:"window"`
+	MaxPerStore     int     `yaml:"max_per_store"`
+	MaxPerTag       int     `yaml:"max_per_tag"`
+	SerendipitySlot int     `yaml:"serendipity_slot"`
+	LongTailLikes   int     `yaml:"long_tail_likes"`
+}
+
+func (d Diversity) validate() []string {
+	var errs []string
+	if d.Lambda < 0 || d.Lambda > 1 {
+		errs = append(errs, "recommend.diversity.lambda must be between 0 and 1")
+	}
+	if d.StoreWeight < 0 || d.TagWeight < 0 {
+		errs = append(errs, "recommend.diversity store_weight and tag_weight must not be negative")
+	}
+	if d.Window <= 0 {
+		errs = append(errs, "recommend.diversity.window must be positive")
+	}
+	if d.MaxPerStore < 0 || d.MaxPerTag < 0 || d.LongTailLikes < 0 {
+		errs = append(errs, "recommend.diversity max_per_store, max_per_tag and long_tail_likes must not be negative")
+	}
+	if d.SerendipitySlot < 0 || d.SerendipitySlot == 1 {
+		errs = append(errs, "recommend.diversity.serendipity_slot must be 0 or at least 2")
+	}
+	return errs
+}
+
+// Precompute 推荐模型的离线计算（ItemCF 近邻表、ALS 训练或内容特征索引）。Interval 为推荐服务内定时重算的间隔，
+// 0 表示服务内不重算（改为定时执行 `recom precompute`），多实例时只有持有租约的实例重算；Reload 为检查并加载新版本的间隔
+type Precompute struct {
+	Interval time.Duration `yaml:"interval"`
+	Reload   time.Duration `yaml:"reload"`
+}
+
+// Notifier 验证码发送渠道，Driver 为 sms 或 log（仅打印日志，用于开发环境）
+type Notifier struct {
+	Driver string `yaml:"driver"`
+	SMS    SMS    `yaml:"sms"`
+}
+
+// SMS 短信网关配置，网关需接受 JSON POST 请求
+type SMS struct {
+	Endpoint string        `yaml:"endpoint"`
+	APIKey   string        `yaml:"api_key"`
+	Sign     string        `yaml:"sign"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// Verify 验证码策略
+type Verify struct {
+	CodeLength        int           `yaml:"code_length"`
+	TTL               time.Duration `yaml:"ttl"`
+	MaxAttempts       int           `yaml:"max_attempts"`
+	ResendInterval    time.Duration `yaml:"resend_interval"`
+	RequireOnRegister bool          `yaml:"require_on_register"`
+}
+
+// RateLimit 令牌桶限流配置，Store 为 memory 或 redis，Rules 以路由规则名为键
+type RateLimit struct {
+	Store string              `yaml:"store"`
+	Redis Redis               `yaml:"redis"`
+	Rules map[string]RateRule `yaml:"rules"`
+}
+
+type Redis struct {
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+// RateRule 每 Period 补充 Limit 个令牌，桶容量为 Burst
+type RateRule struct {
+	Limit  int           `yaml:"limit"`
+	Period time.Duration `yaml:"period"`
+	Burst  int           `yaml:"burst"`
+}
+
+// Lockout 登录失败锁定策略：连续失败 Threshold 次后锁定 BaseDuration，之后每次失败锁定时间翻倍，最长 MaxDuration。
+// 距上次失败超过 ResetAfter 后失败计数清零
+type Lockout struct {
+	Threshold    int           `yaml:"threshold"`
+	BaseDuration time.Duration `yaml:"base_duration"`
+	MaxDuration  time.Duration `yaml:"max_duration"`
+	ResetAfter   time.Duration `yaml:"reset_after"`
+}
+
+// I18n 错误提示语言配置，请求未携带可识别的 Accept-Language 时使用 DefaultLang
+type I18n struct {
+	DefaultLang string `yaml:"default_lang"`
+}
+
+// Log 日志配置，Level 为 debug、info、warn 或 error，Format 为 json 或 text
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// Tracing 链路追踪配置，Exporter 为 none、otlp、stdout 或 file；
+// otlp 通过 gRPC 发送到 Endpoint，file 以 JSON 写入 File，SampleRatio 为根链路采样比例
+type Tracing struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	File        string  `yaml:"file"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Default 返回带默认值的配置，敏感信息必须由配置文件或环境变量提供
+func Default() *Config {
+	return &Config{
+		Server: Server{Addr: ":6001", MetricsAddr: ":6002", ShutdownTimeout: 15 * time.Second, DrainDelay: 5 * time.Second},
+		Database: Database{
+			Driver:          "mysql",
+			MaxOpenConns:    200,
+			MaxIdleConns:    50,
+			ConnMaxLifetime: 10 * time.Minute,
+			AutoMigrate:     true,
+		},
+		JWT: JWT{
+			Issuer:     "Food",
+			AccessTTL:  8 * time.Hour,
+			RefreshTTL: 14 * 24 * time.Hour,
+		},
+		Recommend: Recommend{
+			Addr:            ":8088",
+			Target:          "localhost:8088",
+			Timeout:         5 * time.Second,
+			HealthAddr:      ":8089",
+			ShutdownTimeout: 15 * time.Second,
+			DrainDelay:      5 * time.Second,
+			Keepalive:       30 * time.Second,
+			Retry:           Retry{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second},
+			Breaker:         Breaker{Threshold: 5, Cooldown: 30 * time.Second},
+			Fallback:        Fallback{Size: 100, TTL: time.Minute},
+			Model:           "blend",
+			MaxResults:      100,
+			ItemCF:          ItemCF{Similarity: "cosine", Neighbors: 20, LikeWeight: 3, ClickWeight: 1, DismissWeight: 2},
+			ALS: ALS{
+				Factors:        32,
+				Regularization: 0.1,
+				Iterations:     15,
+				Alpha:          10,
+				LikeWeight:     3,
+				VisitWeight:    0.5,
+				ModelPath:      "models/als.gob",
+			},
+			Content: Content{
+				TagWeight:     1,
+				PriceWeight:   0.5,
+				StoreWeight:   0.5,
+				KeywordWeight: 0.5,
+				PriceBands:    []float64{20, 50, 100},
+				LikeWeight:    3,
+				VisitWeight:   0.5,
+			},
+			Blend: Blend{
+				Normalize: "max",
+				Sources: []BlendSource{
+					{Model: "itemcf", Weight: 1},
+					{Model: "content", Weight: 0.5},
+					{Model: "search", Weight: 0.3},
+					{Model: "popular", Weight: 0.1},
+				},
+			},
+			Diversity: Diversity{
+				Enabled:         true,
+				Lambda:          0.7,
+				StoreWeight:     0.5,
+				TagWeight:       0.5,
+				Window:          10,
+				MaxPerStore:     3,
+				MaxPerTag:       4,
+				SerendipitySlot: 10,
+				LongTailLikes:   5,
+			},
+			Precompute: Precompute{Interval: time.Hour, Reload: time.Minute},
+		},
+		Notifier: Notifier{
+			Driver: "log",
+			SMS:    SMS{Timeout: 5 * time.Second},
+		},
+		Verify: Verify{
+			CodeLength:        6,
+			TTL:               5 * time.Minute,
+			MaxAttempts:       5,
+			ResendInterval:    time.Minute,
+			RequireOnRegister: true,
+		},
+		RateLimit: RateLimit{
+			Store: "memory",
+			Redis: Redis{Addr: "127.0.0.1:6379"},
+			Rules: map[string]RateRule{
+				"login":    {Limit: 10, Period: time.Minute, Burst: 5},
+				"register": {Limit: 5, Period: time.Minute, Burst: 3},
+				"search":   {Limit: 60, Period: time.Minute, Burst: 20},
+				"events":   {Limit: 120, Period: time.Minute, Burst: 30},
+				"verify":   {Limit: 5, Period: 10 * time.Minute, Burst: 3},
+			},
+		},
+		Lockout: Lockout{
+			Threshold:    5,
+			BaseDuration: time.Minute,
+			MaxDuration:  time.Hour,
+			ResetAfter:   24 * time.Hour,
+		},
+		I18n: I18n{DefaultLang: "zh-CN"},
+		Log:  Log{Level: "info", Format: "json"},
+		Tracing: Tracing{
+			Exporter:    "none",
+			Endpoint:    "localhost:4317",
+			Insecure:    true,
+			File:        "traces.json",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load 依次应用默认值、配置文件和环境变量，并校验结果。
+// path 为空时使用 FOOD_CONFIG 或 DefaultPath，默认路径的文件不存在时仅使用默认值和环境变量。
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv("FOOD_CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		path = DefaultPath
+	}
+
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", path, err)
+		}
+		if err := applyDeprecated(cfg, data); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+	default:
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyDeprecated 兼容已改名的配置项：新配置项未设置时沿用旧配置项的值，并提示迁移
+func applyDeprecated(cfg *Config, data []byte) error {
+	var raw struct {
+		Recommend struct {
+			MaxResults *int `yaml:"max_results"`
+			ItemCF     struct {
+				MaxResults *int `yaml:"max_results"`
+			} `yaml:"item_cf"`
+		} `yaml:"recommend"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if legacy := raw.Recommend.ItemCF.MaxResults; legacy != nil {
+		if raw.Recommend.MaxResults == nil {
+			cfg.Recommend.MaxResults = *legacy
+		}
+		slog.Warn("recommend.item_cf.max_results is deprecated, use recommend.max_results",
+			"item_cf.max_results", *legacy, "max_results", cfg.Recommend.MaxResults)
+	}
+	return nil
+}
+
+// MustLoad 同 Load，失败时 panic，供 main 使用
+func MustLoad(path string) *Config {
+	cfg, err := Load(path)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// Validate 校验启动所需的配置项
+func (c *Config) Validate() error {
+	var errs []string
+	if c.Server.Addr == "" {
+		errs = append(errs, "server.addr is required")
+	}
+	if c.Server.ShutdownTimeout <= 0 || c.Recommend.ShutdownTimeout <= 0 {
+		errs = append(errs, "shutdown_timeout must be positive")
+	}
+	if c.Server.DrainDelay < 0 || c.Recommend.DrainDelay < 0 {
+		errs = append(errs, "drain_delay must not be negative")
+	}
+	if c.Database.Driver != "mysql" && c.Database.Driver != "sqlite" {
+		errs = append(errs, "database.driver must be mysql or sqlite")
+	}
+	if c.Database.DSN == "" {
+		errs = append(errs, "database.dsn is required (FOOD_DB_DSN)")
+	}
+	if c.Database.MaxOpenConns <= 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, "database pool sizes must be positive")
+	}
+	if c.JWT.Secret == "" {
+		errs = append(errs, "jwt.secret is required (FOOD_JWT_SECRET)")
+	}
+	if c.JWT.AccessTTL <= 0 || c.JWT.RefreshTTL <= 0 {
+		errs = append(errs, "jwt ttl must be positive")
+	}
+	switch len(c.Crypto.AESKey) {
+	case 16, 24, 32:
+	default:
+		errs = append(errs, "crypto.aes_key must be 16, 24 or 32 bytes (FOOD_AES_KEY)")
+	}
+	if c.Recommend.Addr == "" || c.Recommend.Target == "" || c.Recommend.HealthAddr == "" {
+		errs = append(errs, "recommend.addr, recommend.target and recommend.health_addr are required")
+	}
+	if c.Recommend.Timeout <= 0 {
+		errs = append(errs, "recommend.timeout must be positive")
+	}
+	if c.Recommend.Keepalive < 10*time.Second {
+		errs = append(errs, "recommend.keepalive must be at least 10s")
+	}
+	if r := c.Recommend.Retry; r.MaxAttempts < 1 || r.MaxAttempts > 5 || r.InitialBackoff <= 0 || r.MaxBackoff < r.InitialBackoff {
+		errs = append(errs, "recommend.retry.max_attempts must be between 1 and 5 and backoffs must be positive")
+	}
+	if c.Recommend.Breaker.Threshold <= 0 || c.Recommend.Breaker.Cooldown <= 0 {
+		errs = append(errs, "recommend.breaker threshold and cooldown must be positive")
+	}
+	if c.Recommend.Fallback.Size <= 0 || c.Recommend.Fallback.TTL <= 0 {
+		errs = append(errs, "recommend.fallback size and ttl must be positive")
+	}
+	switch c.Recommend.Model {
+	case "itemcf", "als", "content", "search", "popular":
+	case "blend":
+		errs = append(errs, c.Recommend.Blend.validate()...)
+	default:
+		errs = append(errs, "recommend.model must be itemcf, als, content, search, popular or blend")
+	}
+	if c.Recommend.MaxResults <= 0 {
+		errs = append(errs, "recommend.max_results must be positive")
+	}
+	if s := c.Recommend.ItemCF.Similarity; s != "cosine" && s != "adjusted_cosine" {
+		errs = append(errs, "recommend.item_cf.similarity must be cosine or adjusted_cosine")
+	}
+	if cf := c.Recommend.ItemCF; cf.Neighbors <= 0 || cf.LikeWeight <= 0 {
+		errs = append(errs, "recommend.item_cf neighbors and like_weight must be positive")
+	}
+	if cf := c.Recommend.ItemCF; cf.ClickWeight < 0 || cf.DismissWeight < 0 {
+		errs = append(errs, "recommend.item_cf click_weight and dismiss_weight must not be negative")
+	}
+	if a := c.Recommend.ALS; a.Factors <= 0 || a.Iterations <= 0 || a.Regularization <= 0 || a.Alpha <= 0 || a.LikeWeight <= 0 || a.VisitWeight < 0 {
+		errs = append(errs, "recommend.als factors, iterations, regularization, alpha and like_weight must be positive and visit_weight must not be negative")
+	}
+	if ct := c.Recommend.Content; ct.TagWeight < 0 || ct.PriceWeight < 0 || ct.StoreWeight < 0 || ct.KeywordWeight < 0 || ct.LikeWeight <= 0 || ct.VisitWeight < 0 {
+		errs = append(errs, "recommend.content weights must not be negative and like_weight must be positive")
+	}
+	if !sort.Float64sAreSorted(c.Recommend.Content.PriceBands) {
+		errs = append(errs, "recommend.content.price_bands must be in ascending order")
+	}
+	if c.Recommend.ALS.ModelPath == "" {
+		errs = append(errs, "recommend.als.model_path is required")
+	}
+	experiments := make(map[string]bool, len(c.Experiments))
+	for _, e := range c.Experiments {
+		if experiments[e.Name] {
+			errs = append(errs, fmt.Sprintf("experiments: duplicate name %q", e.Name))
+		}
+		experiments[e.Name] = true
+		errs = append(errs, e.validate()...)
+	}
+	if c.Recommend.Diversity.Enabled {
+		errs = append(errs, c.Recommend.Diversity.validate()...)
+	}
+	if c.Recommend.Precompute.Interval < 0 || c.Recommend.Precompute.Reload <= 0 {
+		errs = append(errs, "recommend.precompute.interval must not be negative and reload must be positive")
+	}
+	switch c.Notifier.Driver {
+	case "log":
+	case "sms":
+		if c.Notifier.SMS.Endpoint == "" {
+			errs = append(errs, "notifier.sms.endpoint is required")
+		}
+	default:
+		errs = append(errs, "notifier.driver must be sms or log")
+	}
+	if c.Verify.CodeLength < 4 || c.Verify.CodeLength > 10 {
+		errs = append(errs, "verify.code_length must be between 4 and 10")
+	}
+	if c.Verify.TTL <= 0 || c.Verify.MaxAttempts <= 0 {
+		errs = append(errs, "verify.ttl and verify.max_attempts must be positive")
+	}
+	switch c.RateLimit.Store {
+	case "memory":
+	case "redis":
+		if c.RateLimit.Redis.Addr == "" {
+			errs = append(errs, "rate_limit.redis.addr is required")
+		}
+	default:
+		errs = append(errs, "rate_limit.store must be memory or redis")
+	}
+	for name, r := range c.RateLimit.Rules {
+		if r.Limit <= 0 || r.Period <= 0 || r.Burst <= 0 {
+			errs = append(errs, fmt.Sprintf("rate_limit.rules.%s must have positive limit, period and burst", name))
+		}
+	}
+	if c.Lockout.Threshold <= 0 || c.Lockout.BaseDuration <= 0 || c.Lockout.MaxDuration < c.Lockout.BaseDuration {
+		errs = append(errs, "lockout threshold and durations must be positive")
+	}
+	if c.I18n.DefaultLang != "zh-CN" && c.I18n.DefaultLang != "en-US" {
+		errs = append(errs, "i18n.default_lang must be zh-CN or en-US")
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, "log.level must be debug, info, warn or error")
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		errs = append(errs, "log.format must be json or text")
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.Tracing.Endpoint == "" {
+			errs = append(errs, "tracing.endpoint is required for the otlp exporter")
+		}
+	case "file":
+		if c.Tracing.File == "" {
+			errs = append(errs, "tracing.file is required for the file exporter")
+		}
+	default:
+		errs = append(errs, "tracing.exporter must be none, otlp, stdout or file")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, "tracing.sample_ratio must be between 0 and 1")
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// 环境变量覆盖表，优先级高于配置文件
+var envOverrides = []struct {
+	key string
+	set func(c *Config, v string) error
+}{
+	{"FOOD_SERVER_ADDR", func(c *Config, v string) error { c.Server.Addr = v; return nil }},
+	{"FOOD_SERVER_METRICS_ADDR", func(c *Config, v string) error { c.Server.MetricsAddr = v; return nil }},
+	{"FOOD_SERVER_SHUTDOWN_TIMEOUT", func(c *Config, v string) error { return setDuration(&c.Server.ShutdownTimeout, v) }},
+	{"FOOD_SERVER_DRAIN_DELAY", func(c *Config, v string) error { return setDuration(&c.Server.DrainDelay, v) }},
+	{"FOOD_DB_DRIVER", func(c *Config, v string) error { c.Database.Driver = v; return nil }},
+	{"FOOD_DB_DSN", func(c *Config, v string) error { c.Database.DSN = v; return nil }},
+	{"FOOD_DB_MAX_OPEN_CONNS", func(c *Config, v string) error { return setInt(&c.Database.MaxOpenConns, v) }},
+	{"FOOD_DB_MAX_IDLE_CONNS", func(c *Config, v string) error { return setInt(&c.Database.MaxIdleConns, v) }},
+	{"FOOD_DB_CONN_MAX_LIFETIME", func(c *Config, v string) error { return setDuration(&c.Database.ConnMaxLifetime, v) }},
+	{"FOOD_DB_AUTO_MIGRATE", func(c *Config, v string) error { return setBool(&c.Database.AutoMigrate, v) }},
+	{"FOOD_JWT_SECRET", func(c *Config, v string) error { c.JWT.Secret = v; return nil }},
+	{"FOOD_JWT_ISSUER", func(c *Config, v string) error { c.JWT.Issuer = v; return nil }},
+	{"FOOD_JWT_ACCESS_TTL", func(c *Config, v string) error { return setDuration(&c.JWT.AccessTTL, v) }},
+	{"FOOD_JWT_REFRESH_TTL", func(c *Config, v string) error { return setDuration(&c.JWT.RefreshTTL, v) }},
+	{"FOOD_AES_KEY", func(c *Config, v string) error { c.Crypto.AESKey = v; return nil }},
+	{"FOOD_RECOMMEND_ADDR", func(c *Config, v string) error { c.Recommend.Addr = v; return nil }},
+	{"FOOD_RECOMMEND_TARGET", func(c *Config, v string) error { c.Recommend.Target = v; return nil }},
+	{"FOOD_RECOMMEND_TIMEOUT", func(c *Config, v string) error { return setDuration(&c.Recommend.Timeout, v) }},
+	{"FOOD_RECOMMEND_HEALTH_ADDR", func(c *Config, v string) error { c.Recommend.HealthAddr = v; return nil }},
+	{"FOOD_RECOMMEND_SHUTDOWN_TIMEOUT", func(c *Config, v string) error { return setDuration(&c.Recommend.ShutdownTimeout, v) }},
+	{"FOOD_RECOMMEND_DRAIN_DELAY", func(c *Config, v string) error { return setDuration(&c.Recommend.DrainDelay, v) }},
+	{"FOOD_RECOMMEND_RETRY_MAX_ATTEMPTS", func(c *Config, v string) error { return setInt(&c.Recommend.Retry.MaxAttempts, v) }},
+	{"FOOD_RECOMMEND_BREAKER_THRESHOLD", func(c *Config, v string) error { return setInt(&c.Recommend.Breaker.Threshold, v) }},
+	{"FOOD_RECOMMEND_BREAKER_COOLDOWN", func(c *Config, v string) error { return setDuration(&c.Recommend.Breaker.Cooldown, v) }},
+	{"FOOD_RECOMMEND_MODEL", func(c *Config, v string) error { c.Recommend.Model = v; return nil }},
+	{"FOOD_RECOMMEND_ITEM_CF_SIMILARITY", func(c *Config, v string) error { c.Recommend.ItemCF.Similarity = v; return nil }},
+	{"FOOD_RECOMMEND_ITEM_CF_NEIGHBORS", func(c *Config, v string) error { return setInt(&c.Recommend.ItemCF.Neighbors, v) }},
+	{"FOOD_RECOMMEND_ALS_MODEL_PATH", func(c *Config, v string) error { c.Recommend.ALS.ModelPath = v; return nil }},
+	{"FOOD_RECOMMEND_PRECOMPUTE_INTERVAL", func(c *Config, v string) error { return setDuration(&c.Recommend.Precompute.Interval, v) }},
+	{"FOOD_NOTIFIER_DRIVER", func(c *Config, v string) error { c.Notifier.Driver = v; return nil }},
+	{"FOOD_SMS_ENDPOINT", func(c *Config, v string) error { c.Notifier.SMS.Endpoint = v; return nil }},
+	{"FOOD_SMS_API_KEY", func(c *Config, v string) error { c.Notifier.SMS.APIKey = v; return nil }},
+	{"FOOD_RATE_LIMIT_STORE", func(c *Config, v string) error { c.RateLimit.Store = v; return nil }},
+	{"FOOD_REDIS_ADDR", func(c *Config, v string) error { c.RateLimit.Redis.Addr = v; return nil }},
+	{"FOOD_REDIS_PASSWORD", func(c *Config, v string) error { c.RateLimit.Redis.Password = v; return nil }},
+	{"FOOD_DEFAULT_LANG", func(c *Config, v string) error { c.I18n.DefaultLang = v; return nil }},
+	{"FOOD_LOG_LEVEL", func(c *Config, v string) error { c.Log.Level = v; return nil }},
+	{"FOOD_LOG_FORMAT", func(c *Config, v string) error { c.Log.Format = v; return nil }},
+	{"FOOD_TRACING_EXPORTER", func(c *Config, v string) error { c.Tracing.Exporter = v; return nil }},
+	{"FOOD_TRACING_ENDPOINT", func(c *Config, v string) error { c.Tracing.Endpoint = v; return nil }},
+	{"FOOD_TRACING_INSECURE", func(c *Config, v string) error { return setBool(&c.Tracing.Insecure, v) }},
+	{"FOOD_TRACING_FILE", func(c *Config, v string) error { c.Tracing.File = v; return nil }},
+	{"FOOD_TRACING_SAMPLE_RATIO", func(c *Config, v string) error { return setFloat(&c.Tracing.SampleRatio, v) }},
+	{"FOOD_VERIFY_REQUIRE_ON_REGISTER", func(c *Config, v string) error { return setBool(&c.Verify.RequireOnRegister, v) }},
+}
+
+func applyEnv(c *Config) error {
+	for _, o := range envOverrides {
+		v, ok := os.LookupEnv(o.key)
+		if !ok {
+			continue
+		}
+		if err := o.set(c, v); err != nil {
+			return fmt.Errorf("env %s: %w", o.key, err)
+		}
+	}
+	return nil
+}
+
+func setInt(dst *int, v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	*dst = n
+	return nil
+}
+
+func setDuration(dst *time.Duration, v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	*dst = d
+	return nil
+}
+
+func setBool(dst *bool, v string) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*dst = b
+	return nil
+}
+
+func setFloat(dst *float64, v string) error {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return err
+	}
+	*dst = f
+	return nil
+}
@@ -1,22 +1,74 @@
 package utils
 
-import "github.com/gin-gonic/gin"
+import (
+	"Food_recommendation/Basic/model"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
 
-func AuthMiddleware() func(c *gin.Context) {
+var (
+	errTokenMissing = model.Unauthorized("token_missing", "authorization token is required")
+	errTokenInvalid = model.Unauthorized("token_invalid", "token is invalid or expired")
+	errTokenRevoked = model.Unauthorized("token_revoked", "token has been revoked")
+	errRoleDenied   = model.Forbidden("forbidden", "access denied for this role")
+)
+
+// AuthMiddleware 校验 access_token，并要求令牌主体属于 roles 之一
+func AuthMiddleware(roles ...Role) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		aToken := c.GetHeader("Authorization")
 		if aToken == "" {
-			c.JSON(401, "Unauthorized")
-			c.Abort()
+			Fail(c, errTokenMissing)
+			return
+		}
+		claims, err := ParasToken(aToken)
+		if err != nil {
+			Fail(c, errTokenInvalid)
+			return
+		}
+		role, id, err := claims.Principal()
+		if err != nil {
+			Fail(c, errTokenInvalid)
+			return
+		}
+		if !hasRole(roles, role) {
+			Fail(c, errRoleDenied)
+			return
+		}
+		// 已登出或已被轮换的令牌在过期前都会出现在拒绝名单中
+		revoked, err := DenyList.IsRevoked(c.Request.Context(), claims.Id)
+		if err != nil {
+			Fail(c, fmt.Errorf("check token deny list: %w", err))
 			return
 		}
-		if claims, err := ParasToken(aToken); err == nil {
-			c.Set("id", claims.ID)
-			c.Next()
-		} else {
-			c.JSON(401, "failed authorize")
-			c.Abort()
+		if revoked {
+			Fail(c, errTokenRevoked)
 			return
 		}
+		c.Set(ctxRoleKey, role)
+		c.Set(ctxIDKey, id)
+		c.Set(ctxSessionKey, claims.Session)
+		c.Next()
+	}
+}
+
+// UserAuth 仅允许普通用户令牌
+func UserAuth() func(c *gin.Context) { return AuthMiddleware(RoleUser) }
+
+// MerchantAuth 仅允许商家令牌
+func MerchantAuth() func(c *gin.Context) { return AuthMiddleware(RoleMerchant) }
+
+// AdminAuth 仅允许管理员令牌
+func AdminAuth() func(c *gin.Context) { return AuthMiddleware(RoleAdmin) }
+
+func hasRole(roles []Role, role Role) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
 	}
+	return false
 }
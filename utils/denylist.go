@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenDenyList access_token 拒绝名单，登出或令牌轮换后旧的 access_token 在过期前都会被拒绝
+type TokenDenyList interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// DenyList 鉴权中间件使用的拒绝名单，默认仅存于进程内存，启动时应替换为持久化实现
+var DenyList TokenDenyList = NewMemoryDenyList()
+
+// MemoryDenyList 进程内拒绝名单，条目过期后自动清理
+type MemoryDenyList struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func NewMemoryDenyList() *MemoryDenyList {
+	return &MemoryDenyList{entries: make(map[string]time.Time)}
+}
+
+func (l *MemoryDenyList) Revoke(_ context.Context, jti string, expiresAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[jti] = expiresAt
+	return nil
+}
+
+func (l *MemoryDenyList) IsRevoked(_ context.Context, jti string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	exp, ok := l.entries[jti]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(exp) {
+		delete(l.entries, jti)
+		return false, nil
+	}
+	return true, nil
+}
+
+// Purge 清理已过期的条目
+func (l *MemoryDenyList) Purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	for jti, exp := range l.entries {
+		if now.After(exp) {
+			delete(l.entries, jti)
+		}
+	}
+}
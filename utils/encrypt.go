@@ -1,13 +1,19 @@
 package utils
 
 import (
+	"Food_recommendation/config"
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/base64"
 )
 
-var key = []byte("something for no") //16，24，32
+var key []byte //16，24，32
+
+// InitCrypto 从配置加载 AES 密钥
+func InitCrypto(c config.Crypto) {
+	key = []byte(c.AESKey)
+}
 
 // 填充函数
 func padding(text []byte, size int) []byte {
@@ -18,7 +24,8 @@ func padding(text []byte, size int) []byte {
 	return res
 }
 
-// Crypto 加密函数
+// Crypto 旧版密码加密函数（AES-CBC 固定 IV，可逆）。
+// 仅用于校验尚未迁移的历史密码，新密码请使用 HashPassword
 func Crypto(text string) (string, error) {
 	txt := []byte(text)
 	block, err := aes.NewCipher(key)
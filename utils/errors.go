@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"Food_recommendation/Basic/model"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"log/slog"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrorBody 统一的错误响应体，error 字段保持与旧接口兼容
+type ErrorBody struct {
+	Code       string             `json:"code"`
+	Error      string             `json:"error"`
+	Details    []model.FieldError `json:"details,omitempty"`
+	RetryAfter int                `json:"retryAfter,omitempty"`
+}
+
+var errInternal = &model.Error{Code: "internal_error", Message: "internal server error"}
+
+// Fail 记录错误并中止请求，响应由 ErrorHandler 统一渲染
+func Fail(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// ErrorHandler 把处理链中记录的错误按请求语言渲染为统一的 JSON 响应。
+// 非领域错误一律返回 internal_error，原始错误只写日志，避免泄露 SQL 等内部信息
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		status, e := classify(c.Errors.Last().Err)
+		if status == http.StatusInternalServerError || status == http.StatusServiceUnavailable {
+			slog.ErrorContext(c.Request.Context(), "request failed", "method", c.Request.Method, "route", c.FullPath(), "status", status, "err", c.Errors.Last().Err)
+		}
+		lang := RequestLang(c)
+		e = localizeError(lang, e)
+		c.Header("Content-Language", string(lang))
+		body := ErrorBody{Code: e.Code, Error: e.Message, Details: e.Details}
+		if e.RetryAfter > 0 {
+			body.RetryAfter = int(math.Ceil(e.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(body.RetryAfter))
+		}
+		c.JSON(status, body)
+	}
+}
+
+func classify(err error) (int, *model.Error) {
+	var e *model.Error
+	if errors.As(err, &e) {
+		return statusOf(e.Kind), e
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, model.NotFound("not_found", "resource not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict, model.Conflict("duplicate_entry", "resource already exists")
+	}
+	return http.StatusInternalServerError, errInternal
+}
+
+func statusOf(kind error) int {
+	switch kind {
+	case model.ErrNotFound:
+		return http.StatusNotFound
+	case model.ErrConflict, model.ErrVersionConflict:
+		return http.StatusConflict
+	case model.ErrValidation:
+		return http.StatusBadRequest
+	case model.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case model.ErrForbidden:
+		return http.StatusForbidden
+	case model.ErrTooManyRequests:
+		return http.StatusTooManyRequests
+	case model.ErrUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// BindError 把请求绑定错误转换为校验错误，字段名取 json 标签
+func BindError(err error) error {
+	var ve validator.ValidationErrors
+	if errors.As(err, &ve) {
+		fields := make([]model.FieldError, 0, len(ve))
+		for _, fe := range ve {
+			fields = append(fields, model.FieldError{Field: fe.Field(), Rule: ruleOf(fe), Param: fe.Param()})
+		}
+		return model.InvalidFields(fields...)
+	}
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) && te.Field != "" {
+		return model.InvalidField(te.Field, "type", "")
+	}
+	return model.Validation("invalid_body", "invalid request body").Wrap(err)
+}
+
+// ruleOf 数值字段的 min/max 表示取值范围而非长度，统一转换为 gte/lte
+func ruleOf(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		switch fe.Tag() {
+		case "min":
+			return "gte"
+		case "max":
+			return "lte"
+		}
+	}
+	return fe.Tag()
+}
+
+func init() {
+	// 校验错误中的字段名使用 json 标签，与请求体保持一致
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"Food_recommendation/config"
+	"hash/fnv"
+	"strconv"
+)
+
+// experimentBuckets 分桶数。Traffic 为整数百分比，换算为桶数后没有误差；分组按 Weight 占比切分桶，误差不超过 0.01%
+const experimentBuckets = 10000
+
+// Assignment 用户所在的实验及分组
+type Assignment struct {
+	Experiment string
+	Variant    string
+	Index      int    // 分组在 Variants 中的下标
+	Model      string // 分组使用的推荐模型，为空时沿用 recommend.model
+}
+
+// AssignExperiment 按配置顺序找到用户参与的第一个已启用实验，返回其分组；不参与任何实验时 ok 为 false。
+// 结果只取决于实验名、分组配置和用户 ID，API 服务和推荐服务各自计算得到相同结果。
+// 是否参与与分到哪组使用不同的哈希，调整 Traffic 时已参与用户的分组不变
+func AssignExperiment(experiments []config.Experiment, userID uint) (Assignment, bool) {
+	for _, e := range experiments {
+		if !e.Enabled || len(e.Variants) == 0 {
+			continue
+		}
+		if experimentBucket(e.Name+":traffic", userID) >= uint64(e.Traffic)*experimentBuckets/100 {
+			continue
+		}
+		total := 0
+		for _, v := range e.Variants {
+			total += v.Weight
+		}
+		pos := int(experimentBucket(e.Name+":variant", userID) * uint64(total) / experimentBuckets)
+		for i, v := range e.Variants {
+			if pos < v.Weight {
+				return Assignment{Experiment: e.Name, Variant: v.Name, Index: i, Model: v.Model}, true
+			}
+			pos -= v.Weight
+		}
+	}
+	return Assignment{}, false
+}
+
+// experimentBucket 返回 [0, experimentBuckets) 内的分桶
+func experimentBucket(salt string, userID uint) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatUint(uint64(userID), 10)))
+	return h.Sum64() % experimentBuckets
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// requestIDMetadata gRPC 元数据键必须小写
+var requestIDMetadata = strings.ToLower(RequestIDHeader)
+
+// RequestIDClientInterceptor 把 ctx 中的请求 ID 作为元数据发送给下游服务
+func RequestIDClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := RequestIDFrom(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestIDMetadata, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDServerInterceptor 从元数据取出请求 ID 写入 ctx（没有时生成新的），并输出调用日志
+func RequestIDServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(requestIDMetadata); len(v) > 0 {
+				id = v[0]
+			}
+		}
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+		ctx = WithRequestID(ctx, id)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		// 健康检查调用频繁，不记录
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return resp, err
+		}
+		attrs := []interface{}{
+			"method", info.FullMethod,
+			"grpc_code", status.Code(err).String(),
+			"latency_ms", time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "grpc request", append(attrs, "err", err)...)
+		} else {
+			slog.InfoContext(ctx, "grpc request", attrs...)
+		}
+		return resp, err
+	}
+}
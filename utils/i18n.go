@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"github.com/gin-gonic/gin"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang 支持的提示语言
+type Lang string
+
+const (
+	LangZH Lang = "zh-CN"
+	LangEN Lang = "en-US"
+)
+
+var defaultLang = LangZH
+
+// InitI18n 从配置加载默认语言
+func InitI18n(c config.I18n) {
+	defaultLang = Lang(c.DefaultLang)
+}
+
+const ctxLangKey = "lang"
+
+// RequestLang 返回当前请求协商出的语言，结果缓存在上下文中
+func RequestLang(c *gin.Context) Lang {
+	if v, ok := c.Get(ctxLangKey); ok {
+		return v.(Lang)
+	}
+	lang := NegotiateLang(c.GetHeader("Accept-Language"))
+	c.Set(ctxLangKey, lang)
+	return lang
+}
+
+// NegotiateLang 按 Accept-Language 的 q 值选择第一个支持的语言，如 "en-GB,en;q=0.8" 选择 en-US
+func NegotiateLang(header string) Lang {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{strings.ToLower(tag), q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		switch {
+		case c.tag == "zh" || strings.HasPrefix(c.tag, "zh-"):
+			return LangZH
+		case c.tag == "en" || strings.HasPrefix(c.tag, "en-"):
+			return LangEN
+		case c.tag == "*":
+			return defaultLang
+		}
+	}
+	return defaultLang
+}
+
+// Translate 返回错误码对应的提示语，目录中没有时使用 fallback
+func Translate(lang Lang, code, fallback string) string {
+	if msg, ok := catalog[lang][code]; ok {
+		return msg
+	}
+	return fallback
+}
+
+// localizeError 返回按语言翻译后的错误副本，字段校验详情逐条翻译
+func localizeError(lang Lang, e *model.Error) *model.Error {
+	out := *e
+	out.Message = Translate(lang, e.Code, e.Message)
+	if len(e.Details) > 0 {
+		out.Details = make([]model.FieldError, len(e.Details))
+		for i, fe := range e.Details {
+			fe.Message = fieldMessage(lang, fe)
+			out.Details[i] = fe
+		}
+		out.Message = out.Details[0].Message
+	}
+	return &out
+}
+
+func fieldMessage(lang Lang, fe model.FieldError) string {
+	tmpl, ok := catalog[lang]["rule."+fe.Rule]
+	if !ok {
+		tmpl, ok = catalog[lang]["rule.default"]
+	}
+	if !ok {
+		return model.FieldMessage(fe.Field, fe.Rule, fe.Param)
+	}
+	field := Translate(lang, "field."+fe.Field, fe.Field)
+	msg := strings.NewReplacer("{field}", field, "{param}", fe.Param).Replace(tmpl)
+	if lang == LangEN && msg != "" {
+		msg = strings.ToUpper(msg[:1]) + msg[1:]
+	}
+	return msg
+}
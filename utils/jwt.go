@@ -1,80 +1,185 @@
 package utils
 
 import (
+	"Food_recommendation/config"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"strconv"
 	"time"
 )
 
-var MySecret = []byte("5cq2up0t")
+var (
+	MySecret   []byte
+	issuer     string
+	aTokenTime time.Duration
+	rTokenTime time.Duration
+)
 
-const aTokenTime = 8 * time.Hour
-const rTokenTime = 14 * 24 * time.Hour
+// InitJWT 从配置加载签名密钥和令牌有效期
+func InitJWT(c config.JWT) {
+	MySecret = []byte(c.Secret)
+	issuer = c.Issuer
+	aTokenTime = c.AccessTTL
+	rTokenTime = c.RefreshTTL
+}
 
 func keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, errors.New("unexpected signing method")
+	}
 	return MySecret, nil
 }
 
-// GenToken 生成 access_token 和 refresh_token
-func GenToken(id string) (aToken, rToken string, err error) {
+// Role 令牌主体类型，同时写入 role 和 aud 声明
+type Role string
+
+const (
+	RoleUser     Role = "user"
+	RoleMerchant Role = "merchant"
+	RoleAdmin    Role = "admin"
+)
+
+// 令牌用途，防止 refresh_token 被当作 access_token 使用
+const (
+	typeAccess  = "access"
+	typeRefresh = "refresh"
+)
+
+// TokenPair 一次签发的令牌对，JTI 和过期时间用于服务端持久化和吊销
+type TokenPair struct {
+	AToken     string
+	RToken     string
+	AJTI       string
+	RJTI       string
+	AExpiresAt time.Time
+	RExpiresAt time.Time
+}
+
+// GenToken 为 session 会话生成 access_token 和 refresh_token
+func GenToken(role Role, id uint, session string) (TokenPair, error) {
+	now := time.Now()
+	pair := TokenPair{
+		AJTI:       NewTokenID(),
+		RJTI:       NewTokenID(),
+		AExpiresAt: now.Add(aTokenTime),
+		RExpiresAt: now.Add(rTokenTime),
+	}
+	var err error
+	if pair.AToken, err = signClaims(role, id, session, typeAccess, pair.AJTI, pair.AExpiresAt); err != nil {
+		return TokenPair{}, err
+	}
+	if pair.RToken, err = signClaims(role, id, session, typeRefresh, pair.RJTI, pair.RExpiresAt); err != nil {
+		return TokenPair{}, err
+	}
+	return pair, nil
+}
+
+// GenAdminToken 签发管理员 access_token，不持久化也没有 refresh_token，到期后需重新签发。
+// 仅供运维通过 `basic token` 命令生成，不对外提供登录接口
+func GenAdminToken(id uint, ttl time.Duration) (string, time.Time, error) {
+	exp := time.Now().Add(ttl)
+	token, err := signClaims(RoleAdmin, id, NewTokenID(), typeAccess, NewTokenID(), exp)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, exp, nil
+}
+
+func signClaims(role Role, id uint, session, typ, jti string, exp time.Time) (string, error) {
 	claims := MyClaims{
-		ID: id,
+		ID:      strconv.FormatUint(uint64(id), 10),
+		Role:    role,
+		Session: session,
+		Type:    typ,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(aTokenTime).Unix(),
-			Issuer:    "Food",
+			Id:        jti,
+			Audience:  string(role),
+			ExpiresAt: exp.Unix(), // 过期时间
+			Issuer:    issuer,     // 签发人
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	aToken, err = token.SignedString(MySecret)
-	// rToken 不需要存储任何自定义数据
-	rToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(rTokenTime).Unix(), // 过期时间
-		Issuer:    "Food",                            // 签发人
-	}).SignedString(MySecret)
-	return aToken, rToken, nil
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(MySecret)
+}
+
+// NewTokenID 生成随机的令牌/会话 ID
+func NewTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
 }
 
 type MyClaims struct {
-	ID string `json:"id"`
+	ID      string `json:"id"`
+	Role    Role   `json:"role"`
+	Session string `json:"sid"`
+	Type    string `json:"typ"`
 	jwt.StandardClaims
 }
 
+// Principal 返回令牌中的主体类型和数字 ID，角色与受众不一致或 ID 非法时返回错误
+func (c *MyClaims) Principal() (Role, uint, error) {
+	if c.Role == "" || !c.VerifyAudience(string(c.Role), true) {
+		return "", 0, errors.New("invalid token role")
+	}
+	id, err := strconv.ParseUint(c.ID, 10, 64)
+	if err != nil || id == 0 {
+		return "", 0, errors.New("invalid token subject")
+	}
+	return c.Role, uint(id), nil
+}
+
 // ParasToken 解析 access_token
-func ParasToken(aToken string) (claims *MyClaims, err error) {
-	var token *jwt.Token
-	claims = new(MyClaims)
-	token, err = jwt.ParseWithClaims(aToken, claims, keyFunc)
+func ParasToken(aToken string) (*MyClaims, error) {
+	return parseToken(aToken, typeAccess)
+}
+
+// ParseRefreshToken 解析 refresh_token，是否已被轮换或吊销需要再查询服务端记录
+func ParseRefreshToken(rToken string) (*MyClaims, error) {
+	return parseToken(rToken, typeRefresh)
+}
+
+func parseToken(tokenString, typ string) (*MyClaims, error) {
+	claims := new(MyClaims)
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
 	if err != nil {
 		return nil, err
 	}
 	if !token.Valid { // token 是否有效
-		err = errors.New("invalidToken")
-		return nil, err
+		return nil, errors.New("invalidToken")
+	}
+	if claims.Type != typ || claims.Id == "" || claims.Session == "" {
+		return nil, errors.New("invalidToken")
 	}
 	return claims, nil
 }
 
-// NewToken 刷新token
-func NewToken(aToken, rToken string) (newToken, newRToken string, err error) {
-	if _, err = jwt.Parse(rToken, keyFunc); err != nil {
-		return "", "", err
-	}
-	var claims MyClaims
-	token, err := jwt.ParseWithClaims(aToken, &claims, keyFunc)
-	if err != nil || !token.Valid {
-		return "", "", errors.New("invalid access_token")
-	}
-	if claims, ok := token.Claims.(*MyClaims); ok && token.Valid {
-		return GenToken(claims.ID)
-	}
-	return "", "", nil
+const (
+	ctxRoleKey    = "role"
+	ctxIDKey      = "id"
+	ctxSessionKey = "sid"
+)
+
+// CurrentPrincipal 返回鉴权中间件写入的主体类型和 ID，未登录时返回空角色和 0
+func CurrentPrincipal(c *gin.Context) (Role, uint) {
+	role, _ := c.Get(ctxRoleKey)
+	id, _ := c.Get(ctxIDKey)
+	r, _ := role.(Role)
+	i, _ := id.(uint)
+	return r, i
 }
 
-func ParseSet(c *gin.Context) string {
-	ID, exists := c.Get("id")
-	if !exists {
-		return ""
-	}
-	return ID.(string)
+// CurrentID 返回当前主体的数字 ID，未登录时返回 0
+func CurrentID(c *gin.Context) uint {
+	_, id := CurrentPrincipal(c)
+	return id
+}
+
+// CurrentSession 返回当前 access_token 所属的会话 ID
+func CurrentSession(c *gin.Context) string {
+	return c.GetString(ctxSessionKey)
 }
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"Food_recommendation/config"
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys 需要脱敏的字段名（忽略大小写和下划线）。
+// 只匹配明确的名称，code 这类通用字段（错误码、gRPC 状态码）不脱敏，验证码须以 verify_code 等名称记录
+var sensitiveKeys = map[string]bool{
+	"password":         true,
+	"oldpassword":      true,
+	"newpassword":      true,
+	"phone":            true,
+	"token":            true,
+	"rtoken":           true,
+	"accesstoken":      true,
+	"refreshtoken":     true,
+	"authorization":    true,
+	"secret":           true,
+	"aeskey":           true,
+	"verifycode":       true,
+	"verificationcode": true,
+	"smscode":          true,
+}
+
+// InitLogger 按配置创建 slog 日志并设为默认日志，同时接管标准库 log 的输出
+func InitLogger(c config.Log) {
+	slog.SetDefault(NewLogger(os.Stderr, c))
+}
+
+// NewLogger 创建结构化日志，自动附带请求 ID 并对敏感字段脱敏
+func NewLogger(w io.Writer, c config.Log) *slog.Logger {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.Level))
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	var h slog.Handler
+	if c.Format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h})
+}
+
+// contextHandler 从 ctx 中取出请求 ID 和链路 ID 写入每条日志
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFrom(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redactAttr 敏感字段直接替换；结构体等复合值按 json 展开后逐个字段脱敏
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if isSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	if a.Value.Kind() == slog.KindAny {
+		if v, ok := redactValue(a.Value.Any()); ok {
+			return slog.Any(a.Key, v)
+		}
+	}
+	return a
+}
+
+func isSensitive(key string) bool {
+	return sensitiveKeys[strings.ToLower(strings.ReplaceAll(key, "_", ""))]
+}
+
+func redactValue(v interface{}) (interface{}, bool) {
+	if _, ok := v.(error); ok {
+		return nil, false
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+	default:
+		return nil, false
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, false
+	}
+	return redactJSON(decoded), true
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if isSensitive(k) {
+				t[k] = redacted
+			} else {
+				t[k] = redactJSON(child)
+			}
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redactJSON(t[i])
+		}
+	}
+	return v
+}
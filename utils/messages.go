@@ -0,0 +1,135 @@
+package utils
+
+// catalog 错误提示目录，以错误码为键；rule.* 为字段校验模板，field.* 为字段显示名
+var catalog = map[Lang]map[string]string{
+	LangZH: {
+		"internal_error":        "服务器内部错误，请稍后重试",
+		"invalid_body":          "请求体格式错误",
+		"validation_failed":     "参数校验失败",
+		"not_found":             "资源不存在",
+		"duplicate_entry":       "资源已存在",
+		"version_conflict":      "数据已被修改，请刷新后重试",
+		"forbidden":             "当前身份无权访问",
+		"store_forbidden":       "该店铺不属于当前商户",
+		"token_missing":         "缺少访问令牌",
+		"token_invalid":         "令牌无效或已过期",
+		"token_revoked":         "令牌已失效，请重新登录",
+		"refresh_token_invalid": "刷新令牌无效或已过期",
+		"refresh_token_reused":  "检测到刷新令牌被重复使用，会话已注销",
+		"bad_credentials":       "账号或密码错误",
+		"account_locked":        "登录失败次数过多，账号暂时锁定",
+		"rate_limited":          "请求过于频繁，请稍后再试",
+		"code_too_frequent":     "验证码发送过于频繁",
+		"code_invalid":          "验证码错误或已过期",
+		"account_not_found":     "账号不存在",
+		"user_not_found":        "用户不存在",
+		"merchant_not_found":    "商户不存在",
+		"store_not_found":       "店铺不存在或未营业",
+		"dish_not_found":        "菜品不存在",
+		"tag_not_found":         "标签不存在",
+		"phone_taken":           "该手机号已被注册",
+		"username_taken":        "该用户名已被使用",
+		"merchant_name_taken":   "该商户名已被使用",
+		"store_name_taken":      "同一商户下已有同名店铺",
+		"dish_name_taken":       "同一店铺下不能有同名菜品",
+		"search_key_exists":     "该搜索关键词已存在",
+		"store_has_dishes":      "店铺下仍有菜品，无法删除",
+		"recommend_unavailable": "推荐服务暂不可用",
+
+		// min/max 只用于字符串和列表长度，数值字段的 min/max 由 ruleOf 转换为 gte/lte
+		"rule.required": "{field}不能为空",
+		"rule.min":      "{field}长度不能少于{param}",
+		"rule.max":      "{field}长度不能超过{param}",
+		"rule.gte":      "{field}不能小于{param}",
+		"rule.lte":      "{field}不能大于{param}",
+		"rule.oneof":    "{field}必须是以下之一：{param}",
+		"rule.phone":    "{field}格式不正确",
+		"rule.url":      "{field}格式无效",
+		"rule.number":   "{field}必须是数字",
+		"rule.decimal":  "{field}必须是有效的金额",
+		"rule.type":     "{field}类型错误",
+		"rule.default":  "{field}无效",
+
+		"field.username":     "用户名",
+		"field.Password":     "密码",
+		"field.password":     "密码",
+		"field.Phone":        "手机号",
+		"field.phone":        "手机号",
+		"field.MerchantName": "商户名",
+		"field.code":         "验证码",
+		"field.purpose":      "用途",
+		"field.rToken":       "刷新令牌",
+		"field.name":         "名称",
+		"field.description":  "描述",
+		"field.address":      "地址",
+		"field.desc":         "菜品描述",
+		"field.price":        "价格",
+		"field.imageUrl":     "图片链接",
+		"field.avgRating":    "评分",
+		"field.score":        "评分",
+		"field.tags":         "标签",
+		"field.key":          "搜索关键词",
+		"field.storeId":      "店铺 ID",
+		"field.dishId":       "菜品 ID",
+		"field.days":         "有效天数",
+		"field.tag":          "标签",
+	},
+	LangEN: {
+		"internal_error":        "Internal server error, please try again later",
+		"invalid_body":          "Invalid request body",
+		"validation_failed":     "Validation failed",
+		"not_found":             "Resource not found",
+		"duplicate_entry":       "Resource already exists",
+		"version_conflict":      "The data has been modified, please refresh and try again",
+		"forbidden":             "Access denied for this role",
+		"store_forbidden":       "The store does not belong to the current merchant",
+		"token_missing":         "Authorization token is required",
+		"token_invalid":         "Token is invalid or expired",
+		"token_revoked":         "Token has been revoked, please log in again",
+		"refresh_token_invalid": "Refresh token is invalid or expired",
+		"refresh_token_reused":  "Refresh token reuse detected, the session has been revoked",
+		"bad_credentials":       "Incorrect account or password",
+		"account_locked":        "Account temporarily locked due to too many failed logins",
+		"rate_limited":          "Too many requests, please try again later",
+		"code_too_frequent":     "Verification code requested too frequently",
+		"code_invalid":          "Verification code is invalid or expired",
+		"account_not_found":     "Account not found",
+		"user_not_found":        "User not found",
+		"merchant_not_found":    "Merchant not found",
+		"store_not_found":       "Store not found or inactive",
+		"dish_not_found":        "Dish not found",
+		"tag_not_found":         "Tag not found",
+		"phone_taken":           "Phone number is already registered",
+		"username_taken":        "Username already exists",
+		"merchant_name_taken":   "Merchant name already exists",
+		"store_name_taken":      "A store with this name already exists under this merchant",
+		"dish_name_taken":       "A dish with this name already exists in this store",
+		"search_key_exists":     "Search keyword already exists",
+		"store_has_dishes":      "Cannot delete a store that still has dishes",
+		"recommend_unavailable": "Recommendation service is unavailable",
+
+		"rule.required": "{field} is required",
+		"rule.min":      "The length of {field} must be at least {param}",
+		"rule.max":      "The length of {field} must be at most {param}",
+		"rule.gte":      "{field} must be greater than or equal to {param}",
+		"rule.lte":      "{field} must be less than or equal to {param}",
+		"rule.oneof":    "{field} must be one of: {param}",
+		"rule.phone":    "{field} is not a valid phone number",
+		"rule.url":      "{field} is not a valid URL",
+		"rule.number":   "{field} must be a number",
+		"rule.decimal":  "{field} must be a valid amount",
+		"rule.type":     "{field} has the wrong type",
+		"rule.default":  "{field} is invalid",
+
+		"field.Password":     "password",
+		"field.Phone":        "phone",
+		"field.MerchantName": "merchant name",
+		"field.rToken":       "refresh token",
+		"field.desc":         "description",
+		"field.imageUrl":     "image URL",
+		"field.avgRating":    "rating",
+		"field.key":          "search keyword",
+		"field.storeId":      "store ID",
+		"field.dishId":       "dish ID",
+	},
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MetricsNamespace 两个服务共用的指标前缀
+const MetricsNamespace = "food"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	grpcHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Name:      "grpc_server_handled_total",
+		Help:      "gRPC calls handled by the server, by method and status code.",
+	}, []string{"method", "code"})
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Name:      "grpc_server_handling_seconds",
+		Help:      "gRPC server handling latency by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// MetricsHandler 暴露 Prometheus 指标
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// HTTPMetrics 按路由模板统计请求数和耗时，未匹配的路由归为 unmatched，避免路径参数造成标签膨胀
+func HTTPMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsServerInterceptor 统计 gRPC 调用数和耗时
+func MetricsServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		grpcHandled.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		grpcDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+// RegisterDBStats 把连接池的 sql.DBStats 注册为指标，重复初始化连接池时替换旧的采集器
+func RegisterDBStats(db *sql.DB, name string) error {
+	c := collectors.NewDBStatsCollector(db, name)
+	err := prometheus.Register(c)
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		prometheus.Unregister(already.ExistingCollector)
+		return prometheus.Register(c)
+	}
+	return err
+}
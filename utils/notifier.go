@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"Food_recommendation/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// Message 待发送的通知，To 为手机号
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier 通知发送渠道，测试中可替换为假实现
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewNotifier 根据配置创建发送渠道
+func NewNotifier(c config.Notifier) (Notifier, error) {
+	switch c.Driver {
+	case "sms":
+		return NewSMSNotifier(c.SMS), nil
+	case "log":
+		return LogNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notifier driver: %s", c.Driver)
+	}
+}
+
+// LogNotifier 只把消息打印到日志，用于本地开发
+type LogNotifier struct{}
+
+func (LogNotifier) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "notification", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// SMSNotifier 通过 HTTP 短信网关发送，网关接收 {"phone","sign","content"} 格式的 JSON
+type SMSNotifier struct {
+	cfg    config.SMS
+	client *http.Client
+}
+
+func NewSMSNotifier(c config.SMS) *SMSNotifier {
+	return &SMSNotifier{cfg: c, client: &http.Client{Timeout: c.Timeout}}
+}
+
+func (n *SMSNotifier) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]string{
+		"phone":   msg.To,
+		"sign":    n.cfg.Sign,
+		"content": msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+n.cfg.APIKey)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send sms failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("send sms failed: gateway returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+// bcryptCost bcrypt 计算成本，调高后旧哈希会在下次登录时自动升级
+const bcryptCost = bcrypt.DefaultCost
+
+// HashPassword 使用 bcrypt 生成密码哈希，每次调用都会生成新的随机盐
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// VerifyPassword 校验明文密码与存储值是否匹配。
+// needsRehash 为 true 表示存储值是旧版 AES 密文或成本过低的 bcrypt 哈希，调用方应在登录成功后重新哈希保存
+func VerifyPassword(stored, password string) (ok bool, needsRehash bool) {
+	if !isBcryptHash(stored) {
+		// 旧版数据：AES 固定 IV 加密，只用于迁移期间的比对
+		legacy, err := Crypto(password)
+		if err != nil {
+			return false, false
+		}
+		ok = subtle.ConstantTimeCompare([]byte(legacy), []byte(stored)) == 1
+		return ok, ok
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)); err != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(stored))
+	return true, err == nil && cost < bcryptCost
+}
+
+func isBcryptHash(s string) bool {
+	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+}
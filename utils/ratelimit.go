@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"Food_recommendation/Basic/model"
+	"Food_recommendation/config"
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LimitStore 令牌桶存储，Take 尝试从 key 对应的桶中取一个令牌
+type LimitStore interface {
+	Take(ctx context.Context, key string, rate float64, burst int) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// NewLimitStore 根据配置创建限流存储
+func NewLimitStore(c config.RateLimit) (LimitStore, error) {
+	switch c.Store {
+	case "memory":
+		return NewMemoryLimitStore(), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     c.Redis.Addr,
+			Password: c.Redis.Password,
+			DB:       c.Redis.DB,
+		})
+		return NewRedisLimitStore(client), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit store: %s", c.Store)
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimitStore 进程内令牌桶，只适用于单实例部署
+type MemoryLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimitStore() *MemoryLimitStore {
+	return &MemoryLimitStore{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+func (s *MemoryLimitStore) Take(_ context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second)), nil
+}
+
+// sweep 每分钟清理一次长时间未访问的桶（此时桶已回满，删除不影响结果）
+func (s *MemoryLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for k, b := range s.buckets {
+		if now.Sub(b.last) > 10*time.Minute {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+// RedisLimitStore 基于 Redis 的令牌桶，多实例共享限流状态，兼容任何支持 Lua 脚本的 Redis 协议服务
+type RedisLimitStore struct {
+	client redis.Scripter
+}
+
+func NewRedisLimitStore(client redis.Scripter) *RedisLimitStore {
+	return &RedisLimitStore{client: client}
+}
+
+// tokenBucketScript 原子地补充令牌并尝试扣减，返回 {是否允许, 需等待毫秒数}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+func (s *RedisLimitStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	res, err := tokenBucketScript.Run(ctx, s.client, []string{key}, rate, burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// RateLimiter 按规则名生成限流中间件
+type RateLimiter struct {
+	store LimitStore
+	rules map[string]config.RateRule
+}
+
+func NewRateLimiter(store LimitStore, rules map[string]config.RateRule) *RateLimiter {
+	return &RateLimiter{store: store, rules: rules}
+}
+
+// Limit 返回名为 name 的限流中间件，桶按路由和请求主体（已登录主体或客户端 IP）区分。
+// 规则未配置时不限流；存储出错时放行，避免限流组件故障导致整站不可用
+func (l *RateLimiter) Limit(name string) gin.HandlerFunc {
+	if l == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	rule, ok := l.rules[name]
+	if !ok {
+		return func(c *gin.Context) { c.Next() }
+	}
+	rate := float64(rule.Limit) / rule.Period.Seconds()
+	return func(c *gin.Context) {
+		key := "rl:" + name + ":" + c.FullPath() + ":" + limitSubject(c)
+		allowed, retryAfter, err := l.store.Take(c.Request.Context(), key, rate, rule.Burst)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "rate limit store error", "rule", name, "err", err)
+			c.Next()
+			return
+		}
+		if !allowed {
+			Fail(c, model.TooManyRequests("rate_limited", "too many requests", retryAfter))
+			return
+		}
+		c.Next()
+	}
+}
+
+// limitSubject 优先使用已登录主体，其次尝试解析请求中的令牌，最后退化为客户端 IP
+func limitSubject(c *gin.Context) string {
+	if role, id := CurrentPrincipal(c); id != 0 {
+		return string(role) + ":" + strconv.FormatUint(uint64(id), 10)
+	}
+	if token := c.GetHeader("Authorization"); token != "" {
+		if claims, err := ParasToken(token); err == nil {
+			if role, id, err := claims.Principal(); err == nil {
+				return string(role) + ":" + strconv.FormatUint(uint64(id), 10)
+			}
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"time"
+)
+
+// RequestIDHeader 请求 ID 的 HTTP 头，gRPC 元数据使用同名小写键
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID 把请求 ID 写入 ctx，之后的 DAO 调用和 gRPC 请求都会带上它
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom 取出 ctx 中的请求 ID，没有时返回空串
+func RequestIDFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID 生成 32 位十六进制随机 ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID 只接受长度有限的字母、数字和 -_.，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID 沿用上游传入的 X-Request-ID，没有或不合法时生成新的，并写回响应头
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// AccessLog 请求结束后输出一条结构化访问日志，路由使用模板路径以免参数散列
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		level := slog.LevelInfo
+		if c.Writer.Status() >= 500 {
+			level = slog.LevelError
+		}
+		slog.Log(c.Request.Context(), level, "http request",
+			"method", c.Request.Method,
+			"route", route,
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		)
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"Food_recommendation/config"
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// 两个服务在链路中的名称
+const (
+	ServiceAPI       = "food-api"
+	ServiceRecommend = "food-recommend"
+)
+
+// flushTimeout 子命令退出前导出剩余 span 的最长等待时间
+const flushTimeout = 5 * time.Second
+
+// ExitAfterFlush 调用 InitTracing 返回的 shutdown 导出剩余 span 后以 code 退出。
+// 子命令结束时使用，os.Exit 不会执行 defer
+func ExitAfterFlush(code int, shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	if err := shutdown(ctx); err != nil {
+		slog.Error("flush traces failed", "err", err)
+	}
+	cancel()
+	os.Exit(code)
+}
+
+// InitTracing 按配置创建全局 TracerProvider，并使用 W3C Trace Context 在进程间传播链路。
+// 返回的 shutdown 在退出前调用，把缓冲中的 span 全部导出
+func InitTracing(ctx context.Context, c config.Tracing, service string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if c.Exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		file     *os.File
+		err      error
+	)
+	switch c.Exporter {
+	case "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.Endpoint)}
+		if c.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "file":
+		file, err = os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", c.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", c.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service)))
+	if err != nil {
+		return nil, fmt.Errorf("create trace resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if file != nil {
+			err = errors.Join(err, file.Close())
+		}
+		return err
+	}, nil
+}